@@ -1,7 +1,11 @@
 package sgh
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -9,96 +13,183 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
-var defaultClient = NewSimpleClient()
+var defaultClient SimpleHttp = NewSimpleClient()
 
 type SimpleClient struct {
-	client  *fasthttp.Client
-	timeout time.Duration
+	transport    Transport
+	timeout      time.Duration
+	statusPolicy StatusPolicy
+	retry        *RetryPolicy
+	hooks        []Hook
+	middlewares  []Middleware
+	baseURL      string
+	header       http.Header
+	codecs       *codecRegistry
+	logger       Logger
+	debug        bool
+	logBodyLimit int
+	redactor     *Redactor
+	queryOptions utils.QueryOptions
 }
 
-func NewSimpleClient() *SimpleClient {
-	return &SimpleClient{
-		client:  &fasthttp.Client{},
-		timeout: 30 * time.Second,
+func NewSimpleClient(opts ...Option) *SimpleClient {
+	sc := &SimpleClient{
+		transport:    &FastTransport{Client: &fasthttp.Client{}},
+		timeout:      30 * time.Second,
+		statusPolicy: Status2xx,
+		header:       http.Header{},
+		codecs:       newCodecRegistry(defaultCodecs),
+		logBodyLimit: defaultLogBodyLimit,
+		redactor:     DefaultRedactor(),
 	}
+	for _, f := range opts {
+		f(sc)
+	}
+	return sc
 }
 
 func (sc *SimpleClient) SetTimeout(timeout time.Duration) {
 	sc.timeout = timeout
 }
 
-func (sc *SimpleClient) Do(req *Request, resp *Response, opts ...func(*Request, *Response)) error {
-	for _, f := range opts {
-		f(req, resp)
-	}
+// SetTransport 替换底层 Transport，默认为基于 fasthttp 的 FastTransport。
+func (sc *SimpleClient) SetTransport(transport Transport) {
+	sc.transport = transport
+}
 
-	rq := fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(rq)
-	rp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(rp)
-	request2fastRequest(req, rq)
+// SetStatusPolicy 设置判断响应成功的策略，nil 表示接受任意状态码。
+func (sc *SimpleClient) SetStatusPolicy(policy StatusPolicy) {
+	sc.statusPolicy = policy
+}
 
-	timeout := sc.timeout
-	if req.Timeout != 0 {
-		timeout = req.Timeout
+// SetRetryPolicy 设置重试策略，nil 表示不重试。
+func (sc *SimpleClient) SetRetryPolicy(policy *RetryPolicy) {
+	sc.retry = policy
+}
+
+// SetLogger 设置日志的输出位置，nil 表示写入 stderr，是否记录由 SetDebug 与 Request.SetDebug 决定。
+func (sc *SimpleClient) SetLogger(logger Logger) {
+	sc.logger = logger
+}
+
+// SetDebug 设置是否默认记录所有请求与响应，可被 Request.SetDebug 覆盖。
+func (sc *SimpleClient) SetDebug(debug bool) {
+	sc.debug = debug
+}
+
+// RegisterCodec 为当前客户端注册 bt 的编解码方式，优先于全局的 RegisterCodec。
+func (sc *SimpleClient) RegisterCodec(bt BodyType, codec Codec) {
+	sc.codecs.register(bt, codec)
+}
+
+// RegisterMediaCodec 为当前客户端注册 Default 模式下 mediaType 的解析方式。
+func (sc *SimpleClient) RegisterMediaCodec(mediaType string, codec Codec) {
+	sc.codecs.registerMedia(mediaType, codec)
+}
+
+// AddHooks 注册全局钩子，先于 Do 传入的钩子执行。
+func (sc *SimpleClient) AddHooks(hooks ...Hook) {
+	sc.hooks = append(sc.hooks, hooks...)
+}
+
+func (sc *SimpleClient) Do(req *Request, resp *Response, hooks ...Hook) (err error) {
+	if resp == nil {
+		resp = &Response{}
 	}
+	chain := make([]Hook, 0, len(sc.hooks)+len(hooks))
+	chain = append(append(chain, sc.hooks...), hooks...)
 
-	if err := sc.client.DoTimeout(rq, rp, timeout); err != nil {
+	defer func() {
+		if err != nil {
+			resp.Close()
+			runOnError(chain, req, resp, err)
+		}
+	}()
+	if err = runBeforeRequest(chain, req); err != nil {
 		return err
 	}
-	if err := fastResponse2Response(rp, resp); err != nil {
+	if err = sc.do(req, resp); err != nil {
 		return err
 	}
+	return runAfterResponse(chain, req, resp)
+}
 
-	for _, f := range opts {
-		f(req, resp)
+func (sc *SimpleClient) do(req *Request, resp *Response) error {
+	if req.Ctx != nil && req.Ctx.Err() != nil {
+		return &ContextError{Err: req.Ctx.Err()}
+	}
+
+	// 请求体只构建一次，重试时复用；编码失败时不会发起请求
+	call, err := sc.Build(req)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := sc.handler()(call, resp); err != nil {
+		return err
+	}
+	resp.StartTime = start
+	resp.Duration = time.Since(start)
+	if resp.Stream && resp.Body == nil {
+		// 自定义 Transport（如 sghtest 的 mock）只填充了 RawBody
+		resp.Body = ioutil.NopCloser(bytes.NewReader(resp.RawBody))
+		resp.RawBody = nil
 	}
-	return nil
-}
 
-func request2fastRequest(req *Request, rq *fasthttp.Request) {
-	method, url, header, body := req.build()
-	rq.AppendBody(body)
-	rq.SetRequestURI(url)
-	for k, v := range header {
-		if len(v) > 0 {
-			rq.Header.Set(k, v[0])
-		} else {
-			rq.Header.Set(k, "")
+	policy := sc.statusPolicy
+	if req.StatusPolicy != nil {
+		policy = req.StatusPolicy
+	}
+	if policy != nil && !policy(resp.StatusCode) {
+		if resp.Body != nil {
+			// 错误响应体通常很小，读取后供 HTTPError 使用
+			resp.RawBody, _ = ioutil.ReadAll(resp.Body)
+			resp.Close()
 		}
+		return newHTTPError(resp, sc.codecs)
+	}
+	if resp.Stream {
+		return nil
 	}
-	rq.Header.SetMethod(method.String())
+	return decodeBody(resp, resp.Result, sc.codecs)
 }
 
-func fastResponse2Response(rs *fasthttp.Response, resp *Response) (err error) {
-	// safe check
-	if resp == nil || resp.Result == nil {
-		return nil
+// Build 按当前客户端的配置构建 req 实际发送的 Call，不发起请求。
+func (sc *SimpleClient) Build(req *Request) (*Call, error) {
+	call, err := req.build(sc.codecs, sc.queryOptions)
+	if err != nil {
+		return nil, err
 	}
 
-	head := http.Header{}
-	rs.Header.VisitAll(func(k, v []byte) {
-		head.Add(string(k), string(v))
-	})
-	resp.Header = head
-
-	rt := resp.ResultType
-	if rt == Default {
-		switch strings.ToLower(head.Get("Content-Type")) {
-		case "application/json":
-			rt = Json
-		case "application/xml":
-			rt = Xml
-		default:
-			rt = Json
+	call.Ctx = req.Ctx
+	if call.Ctx == nil {
+		call.Ctx = context.Background()
+	}
+	call.Timeout = sc.timeout
+	if req.Timeout != 0 {
+		call.Timeout = req.Timeout
+	}
+	call.URL = resolveURL(sc.baseURL, call.URL)
+	for k, v := range sc.header {
+		if _, ok := call.Header[k]; !ok {
+			call.Header[k] = append([]string(nil), v...)
 		}
 	}
+	return call, nil
+}
 
-	switch rt {
-	case Json:
-		err = utils.Json2Struct(rs.Body(), resp.Result)
-	case Xml:
-		err = utils.Xml2Struct(rs.Body(), resp.Result)
+// resolveURL 将相对地址拼接到 base 之后，绝对地址保持不变。
+func resolveURL(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	// 仅以 scheme 开头的地址为绝对地址，查询参数中的 :// 不影响判断
+	if u, err := url.Parse(ref); err == nil && u.IsAbs() {
+		return ref
+	}
+	if ref == "" || strings.HasPrefix(ref, "?") || strings.HasPrefix(ref, "#") {
+		return base + ref
 	}
-	return
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(ref, "/")
 }
@@ -0,0 +1,331 @@
+package sgh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSimpleClient_Do_Context(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"key":"value"}`))
+	}))
+	defer srv.Close()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		timeout time.Duration
+		wantErr error
+	}{
+		{
+			name:    "no context.",
+			ctx:     func() (context.Context, context.CancelFunc) { return nil, func() {} },
+			wantErr: nil,
+		},
+		{
+			name:    "context already canceled.",
+			ctx:     func() (context.Context, context.CancelFunc) { return canceled, func() {} },
+			wantErr: context.Canceled,
+		},
+		{
+			name: "cancel in flight.",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "context deadline earlier than timeout.",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			timeout: time.Second,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			var rs map[string]string
+			start := time.Now()
+			err := NewSimpleClient().Do(
+				NewRequest().Get(srv.URL).Context(ctx).SetTimeout(tt.timeout),
+				NewDefaultResponse(&rs),
+			)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("SimpleClient.Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && time.Since(start) > 300*time.Millisecond {
+				t.Errorf("SimpleClient.Do() returned after %v, want abort before server responds", time.Since(start))
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_ResponseMeta(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Test", "value")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not found"}`))
+	}))
+	defer srv.Close()
+
+	var rs map[string]string
+	tests := []struct {
+		name string
+		resp *Response
+	}{
+		{name: "with result.", resp: NewDefaultResponse(&rs)},
+		{name: "without result.", resp: NewDefaultResponse(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewSimpleClient().Do(NewRequest().Get(srv.URL).SetStatusPolicy(AnyStatus), tt.resp); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if tt.resp.StatusCode != http.StatusNotFound {
+				t.Errorf("Response.StatusCode = %v, want %v", tt.resp.StatusCode, http.StatusNotFound)
+			}
+			if tt.resp.Status != "404 Not Found" {
+				t.Errorf("Response.Status = %v, want %v", tt.resp.Status, "404 Not Found")
+			}
+			if tt.resp.Proto != "HTTP/1.1" {
+				t.Errorf("Response.Proto = %v, want %v", tt.resp.Proto, "HTTP/1.1")
+			}
+			if string(tt.resp.RawBody) != `{"error":"not found"}` {
+				t.Errorf("Response.RawBody = %s, want %s", tt.resp.RawBody, `{"error":"not found"}`)
+			}
+			if tt.resp.ContentLength != int64(len(tt.resp.RawBody)) {
+				t.Errorf("Response.ContentLength = %v, want %v", tt.resp.ContentLength, len(tt.resp.RawBody))
+			}
+			if tt.resp.Header.Get("X-Test") != "value" {
+				t.Errorf("Response.Header = %v, want X-Test: value", tt.resp.Header)
+			}
+			if tt.resp.StartTime.IsZero() || tt.resp.Duration <= 0 {
+				t.Errorf("Response timing not set: start %v, duration %v", tt.resp.StartTime, tt.resp.Duration)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"key":"value"}`))
+	}))
+	defer srv.Close()
+
+	type apiError struct {
+		Message string `json:"message"`
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		clientPolicy StatusPolicy
+		reqPolicy    StatusPolicy
+		wantStatus   int
+		wantResult   map[string]string
+		wantApiErr   apiError
+	}{
+		{
+			name:       "2xx decode result.",
+			url:        srv.URL,
+			wantResult: map[string]string{"key": "value"},
+		},
+		{
+			name:       "5xx decode error result.",
+			url:        srv.URL + "?fail=1",
+			wantStatus: http.StatusInternalServerError,
+			wantApiErr: apiError{Message: "boom"},
+		},
+		{
+			name:         "client accept any status.",
+			url:          srv.URL + "?fail=1",
+			clientPolicy: AnyStatus,
+			wantResult:   map[string]string{"message": "boom"},
+		},
+		{
+			name:         "request policy override client policy.",
+			url:          srv.URL + "?fail=1",
+			clientPolicy: AnyStatus,
+			reqPolicy:    Status2xx,
+			wantStatus:   http.StatusInternalServerError,
+			wantApiErr:   apiError{Message: "boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := NewSimpleClient()
+			if tt.clientPolicy != nil {
+				sc.SetStatusPolicy(tt.clientPolicy)
+			}
+			var result map[string]string
+			var apiErr apiError
+			err := sc.Do(
+				NewRequest().Get(tt.url).SetStatusPolicy(tt.reqPolicy),
+				NewJsonResponse(&result).OnError(&apiErr),
+			)
+
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("SimpleClient.Do() error = %v, want nil", err)
+				}
+			} else {
+				var httpErr *HTTPError
+				if !errors.As(err, &httpErr) {
+					t.Fatalf("SimpleClient.Do() error = %v, want *HTTPError", err)
+				}
+				if httpErr.StatusCode != tt.wantStatus || httpErr.Result != &apiErr || string(httpErr.Body) != `{"message":"boom"}` {
+					t.Errorf("HTTPError = %+v", httpErr)
+				}
+			}
+			if !reflect.DeepEqual(result, tt.wantResult) {
+				t.Errorf("Response.Result = %v, want %v", result, tt.wantResult)
+			}
+			if apiErr != tt.wantApiErr {
+				t.Errorf("Response.ErrorResult = %v, want %v", apiErr, tt.wantApiErr)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_Form(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		w.Write([]byte(r.PostForm.Encode()))
+	}))
+	defer srv.Close()
+
+	type form struct {
+		Name string   `url:"name"`
+		Age  int      `url:"age"`
+		Tags []string `url:"tags"`
+	}
+	want := form{Name: "a&b c", Age: 18, Tags: []string{"x", "y"}}
+
+	var got form
+	err := NewSimpleClient().Do(
+		NewRequest().Post(srv.URL, want).SetRequestType(Form),
+		NewDefaultResponse(&got),
+	)
+	if err != nil {
+		t.Fatalf("SimpleClient.Do() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Response.Result = %v, want %v", got, want)
+	}
+}
+
+func TestSimpleClient_Do_BodyStream(t *testing.T) {
+	type echo struct {
+		ContentLength    int64    `json:"content_length"`
+		TransferEncoding []string `json:"transfer_encoding"`
+		ContentType      string   `json:"content_type"`
+		Body             string   `json:"body"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(echo{
+			ContentLength:    r.ContentLength,
+			TransferEncoding: r.TransferEncoding,
+			ContentType:      r.Header.Get("Content-Type"),
+			Body:             string(body),
+		})
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		req  func() *Request
+		want echo
+	}{
+		{
+			name: "length from reader.",
+			req: func() *Request {
+				return NewRequest().Post(srv.URL, strings.NewReader(`{"a":1}`)).SetRequestType(Json)
+			},
+			want: echo{ContentLength: 7, ContentType: "application/json", Body: `{"a":1}`},
+		},
+		{
+			name: "explicit length.",
+			req: func() *Request {
+				return NewRequest().HttpMethod(PUT).Url(srv.URL).SetBodyStream(io.MultiReader(strings.NewReader("abc")), 3)
+			},
+			want: echo{ContentLength: 3, ContentType: "application/octet-stream", Body: "abc"},
+		},
+		{
+			name: "unknown length use chunked.",
+			req: func() *Request {
+				return NewRequest().HttpMethod(PUT).Url(srv.URL).SetBodyStream(io.MultiReader(strings.NewReader("abc")), 0)
+			},
+			want: echo{ContentLength: -1, TransferEncoding: []string{"chunked"}, ContentType: "application/octet-stream", Body: "abc"},
+		},
+	}
+
+	transports := map[string]Transport{
+		"fasthttp": NewSimpleClient().transport,
+		"net/http": NewHTTPTransport(nil),
+	}
+	for name, transport := range transports {
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				var got echo
+				err := NewSimpleClient(WithTransport(transport)).Do(tt.req(), NewJsonResponse(&got))
+				if err != nil {
+					t.Fatalf("SimpleClient.Do() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Response.Result = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSimpleClient_Do_EncodeError(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer srv.Close()
+
+	err := NewSimpleClient().Do(NewRequest().Post(srv.URL, make(chan int)), nil)
+	var encodeErr *EncodeError
+	if !errors.As(err, &encodeErr) || encodeErr.BodyType != Json {
+		t.Fatalf("SimpleClient.Do() error = %v, want *EncodeError", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 0 {
+		t.Errorf("server hits = %v, want 0", got)
+	}
+}
@@ -0,0 +1,159 @@
+package sgh
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
+)
+
+// Codec 负责请求体的编码与响应体的解析。
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType 为请求的 Content-Type，为空时不设置
+	ContentType() string
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return utils.Struct2Json(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return utils.Json2Struct(data, v) }
+func (jsonCodec) ContentType() string                        { return "application/json" }
+
+// LegacyJsonCodec 保留旧版的 Json 编码行为：基础类型按 fmt 格式原样发送，
+// 如字符串 single 不加引号。可通过 RegisterCodec(Json, LegacyJsonCodec{}) 等方式启用。
+type LegacyJsonCodec struct {
+	jsonCodec
+}
+
+func (LegacyJsonCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v.(type) {
+	case string, int8, int16, int32, int, int64, float32, float64,
+		uint8, uint16, uint32, uint, uint64, bool, uintptr:
+		return []byte(fmt.Sprintf("%v", v)), nil
+	}
+	return utils.Struct2Json(v)
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error)      { return utils.Struct2Xml(v) }
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error { return utils.Xml2Struct(data, v) }
+func (xmlCodec) ContentType() string                        { return "application/xml" }
+
+// queryCodec 用于 UrlQuery 与 Form，两者仅 Content-Type 不同。
+type queryCodec struct {
+	contentType string
+}
+
+func (queryCodec) Marshal(v interface{}) ([]byte, error)      { return utils.Struct2UrlQuery(v) }
+func (queryCodec) Unmarshal(data []byte, v interface{}) error { return utils.UrlQuery2Struct(data, v) }
+func (c queryCodec) ContentType() string                      { return c.contentType }
+
+// marshal 按 Request 或 SimpleClient 的 QueryOptions 编码。
+func (queryCodec) marshal(v interface{}, opts utils.QueryOptions) ([]byte, error) {
+	return utils.Struct2UrlQueryWithOptions(v, opts)
+}
+
+var formCodec = queryCodec{contentType: "application/x-www-form-urlencoded; charset=utf-8"}
+
+// codecRegistry 按 BodyType 与媒体类型查找 Codec，未找到时继续查找 parent。
+type codecRegistry struct {
+	mu     sync.RWMutex
+	types  map[BodyType]Codec
+	media  map[string]Codec
+	parent *codecRegistry
+}
+
+func newCodecRegistry(parent *codecRegistry) *codecRegistry {
+	return &codecRegistry{
+		types:  map[BodyType]Codec{},
+		media:  map[string]Codec{},
+		parent: parent,
+	}
+}
+
+var defaultCodecs = &codecRegistry{
+	types: map[BodyType]Codec{
+		Json:      jsonCodec{},
+		Xml:       xmlCodec{},
+		UrlQuery:  queryCodec{},
+		Form:      formCodec,
+		Protobuf:  protobufCodec{},
+		ProtoJson: protoJsonCodec{},
+		Msgpack:   msgpackCodec{},
+		Cbor:      cborCodec{},
+	},
+	media: map[string]Codec{
+		"application/json":                  jsonCodec{},
+		"application/xml":                   xmlCodec{},
+		"text/xml":                          xmlCodec{},
+		"application/x-www-form-urlencoded": formCodec,
+		"application/x-protobuf":            protobufCodec{},
+		"application/protobuf":              protobufCodec{},
+		"application/msgpack":               msgpackCodec{},
+		"application/x-msgpack":             msgpackCodec{},
+		"application/vnd.msgpack":           msgpackCodec{},
+		"application/cbor":                  cborCodec{},
+	},
+}
+
+// RegisterCodec 为所有客户端注册 bt 的编解码方式，
+// 同时注册到 codec.ContentType() 的媒体类型，用于 Default 模式的解析。
+func RegisterCodec(bt BodyType, codec Codec) {
+	defaultCodecs.register(bt, codec)
+}
+
+// RegisterMediaCodec 为所有客户端注册 Default 模式下 mediaType 的解析方式。
+func RegisterMediaCodec(mediaType string, codec Codec) {
+	defaultCodecs.registerMedia(mediaType, codec)
+}
+
+func (r *codecRegistry) register(bt BodyType, codec Codec) {
+	r.mu.Lock()
+	r.types[bt] = codec
+	r.mu.Unlock()
+	if mediaType, _, err := mime.ParseMediaType(codec.ContentType()); err == nil {
+		r.registerMedia(mediaType, codec)
+	}
+}
+
+func (r *codecRegistry) registerMedia(mediaType string, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.media[strings.ToLower(mediaType)] = codec
+}
+
+func (r *codecRegistry) byType(bt BodyType) Codec {
+	for ; r != nil; r = r.parent {
+		r.mu.RLock()
+		codec, ok := r.types[bt]
+		r.mu.RUnlock()
+		if ok {
+			return codec
+		}
+	}
+	return nil
+}
+
+// byMedia 按媒体类型查找 Codec，未注册时按 +json、+xml 后缀查找。
+func (r *codecRegistry) byMedia(mediaType string) Codec {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mu.RLock()
+		codec, ok := reg.media[mediaType]
+		reg.mu.RUnlock()
+		if ok {
+			return codec
+		}
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return r.byMedia("application/json")
+	case strings.HasSuffix(mediaType, "+xml"):
+		return r.byMedia("application/xml")
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package sgh
+
+import (
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborCodec 优先使用 cbor tag，没有时使用 json tag。
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v interface{}) error { return cbor.Unmarshal(data, v) }
+func (cborCodec) ContentType() string                        { return "application/cbor" }
@@ -0,0 +1,58 @@
+package sgh
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, err := protoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, err := protoMessage(v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+// protoJsonCodec 使用 protojson 编解码，Content-Type 与 Json 相同，
+// 因此不参与 Default 模式的自动识别。
+type protoJsonCodec struct{}
+
+func (protoJsonCodec) Marshal(v interface{}) ([]byte, error) {
+	m, err := protoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(m)
+}
+
+func (protoJsonCodec) Unmarshal(data []byte, v interface{}) error {
+	m, err := protoMessage(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+func (protoJsonCodec) ContentType() string { return "application/json" }
+
+func protoMessage(v interface{}) (proto.Message, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("sgh: protobuf expects proto.Message, got %T", v)
+	}
+	return m, nil
+}
@@ -0,0 +1,71 @@
+package sgh
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSimpleClient_Do_Protobuf(t *testing.T) {
+	// 服务端按请求的 Content-Type 解析，返回转为大写的同类型消息
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		in := &wrapperspb.StringValue{}
+		var err error
+		if r.Header.Get("Content-Type") == "application/x-protobuf" {
+			err = proto.Unmarshal(body, in)
+		} else {
+			err = protojson.Unmarshal(body, in)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		out := wrapperspb.String(in.Value + "!")
+		if r.Header.Get("Content-Type") == "application/x-protobuf" {
+			body, _ = proto.Marshal(out)
+		} else {
+			body, _ = protojson.Marshal(out)
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		reqType BodyType
+		resp    func(msg proto.Message) *Response
+	}{
+		{name: "protobuf.", reqType: Protobuf, resp: NewProtobufResponse},
+		{name: "protobuf auto detect.", reqType: Protobuf, resp: func(msg proto.Message) *Response { return NewDefaultResponse(msg) }},
+		{name: "protojson.", reqType: ProtoJson, resp: NewProtoJsonResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &wrapperspb.StringValue{}
+			req := NewRequest().Post(srv.URL, wrapperspb.String("hi")).SetRequestType(tt.reqType)
+			if err := NewSimpleClient().Do(req, tt.resp(got)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if got.Value != "hi!" {
+				t.Errorf("Response.Result = %v, want %v", got.Value, "hi!")
+			}
+		})
+	}
+}
+
+func TestProtobufCodec_notMessage(t *testing.T) {
+	if _, err := (protobufCodec{}).Marshal(struct{}{}); err == nil {
+		t.Errorf("protobufCodec.Marshal() expect error for non proto.Message")
+	}
+	var s string
+	if err := (protoJsonCodec{}).Unmarshal([]byte(`"a"`), &s); err == nil {
+		t.Errorf("protoJsonCodec.Unmarshal() expect error for non proto.Message")
+	}
+}
@@ -0,0 +1,156 @@
+package sgh
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upperCodec 编码为大写文本，解析为小写文本。
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(v.(string))), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*string) = strings.ToLower(string(data))
+	return nil
+}
+
+func (upperCodec) ContentType() string { return "text/x-upper" }
+
+func TestSimpleClient_Do_Codec(t *testing.T) {
+	const Upper BodyType = 100
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		client *SimpleClient
+		req    func() *Request
+		resp   func(v *string) *Response
+		want   string
+	}{
+		{
+			name:   "client codec by body type.",
+			client: NewSimpleClient(WithCodec(Upper, upperCodec{})),
+			req:    func() *Request { return NewRequest().Post(srv.URL, "Hello").SetRequestType(Upper) },
+			resp:   func(v *string) *Response { return NewDefaultResponse(v) },
+			want:   "hello",
+		},
+		{
+			name:   "request and response codec.",
+			client: NewSimpleClient(),
+			req:    func() *Request { return NewRequest().Post(srv.URL, "Hello").SetCodec(upperCodec{}) },
+			resp:   func(v *string) *Response { return NewDefaultResponse(v).SetCodec(upperCodec{}) },
+			want:   "hello",
+		},
+		{
+			name:   "text fallback without codec.",
+			client: NewSimpleClient(),
+			req:    func() *Request { return NewRequest().Post(srv.URL, "Hello").SetCodec(upperCodec{}) },
+			resp:   func(v *string) *Response { return NewDefaultResponse(v) },
+			want:   "HELLO",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if err := tt.client.Do(tt.req(), tt.resp(&got)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Response.Result = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_codecRegistry(t *testing.T) {
+	global := newCodecRegistry(defaultCodecs)
+	client := newCodecRegistry(global)
+	global.registerMedia("application/yaml", upperCodec{})
+	client.register(Json, upperCodec{})
+
+	tests := []struct {
+		name      string
+		bt        BodyType
+		mediaType string
+		want      Codec
+	}{
+		{name: "client override.", bt: Json, want: upperCodec{}},
+		{name: "builtin.", bt: Xml, want: xmlCodec{}},
+		{name: "unknown body type.", bt: 100, want: nil},
+		{name: "media from content type.", mediaType: "text/x-upper", want: upperCodec{}},
+		{name: "media from parent.", mediaType: "application/yaml", want: upperCodec{}},
+		{name: "json suffix.", mediaType: "application/problem+json", want: jsonCodec{}},
+		{name: "xml suffix.", mediaType: "application/atom+xml", want: xmlCodec{}},
+		{name: "unknown media.", mediaType: "application/octet-stream", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Codec
+			if tt.mediaType != "" {
+				got = client.byMedia(tt.mediaType)
+			} else {
+				got = client.byType(tt.bt)
+			}
+			if got != tt.want {
+				t.Errorf("codecRegistry lookup = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_BinaryCodec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	type item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+		Page int      `json:"page" msgpack:"p" cbor:"p"`
+	}
+	want := item{Name: "a", Tags: []string{"x", "y"}, Page: 2}
+
+	tests := []struct {
+		name        string
+		reqType     BodyType
+		resp        func(v interface{}) *Response
+		contentType string
+	}{
+		{name: "msgpack.", reqType: Msgpack, resp: NewMsgpackResponse, contentType: "application/msgpack"},
+		{name: "msgpack auto detect.", reqType: Msgpack, resp: NewDefaultResponse, contentType: "application/msgpack"},
+		{name: "cbor.", reqType: Cbor, resp: NewCborResponse, contentType: "application/cbor"},
+		{name: "cbor auto detect.", reqType: Cbor, resp: NewDefaultResponse, contentType: "application/cbor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got item
+			resp := tt.resp(&got)
+			err := NewSimpleClient().Do(NewRequest().Post(srv.URL, want).SetRequestType(tt.reqType), resp)
+			if err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %v, want %v", ct, tt.contentType)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Response.Result = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package sgh
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
+)
+
+// decodeBody 将 resp.RawBody 按 resp.Codec 或 resp.ResultType 对应的 Codec 解析到 v。
+// Default 模式根据 Content-Type 选择 Codec，未知类型保持 v 不变，
+// text/* 类型可解析到 *string 或 *[]byte，text/* 与 xml 类型会按 charset 转换为 UTF-8。
+func decodeBody(resp *Response, v interface{}, codecs *codecRegistry) error {
+	// safe check
+	if v == nil {
+		return nil
+	}
+
+	// 解析参数出错时 mime 仍会返回媒体类型
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body := resp.RawBody
+	// 仅文本与 xml 按 charset 转码，json 与二进制格式保持原样
+	if charset := params["charset"]; charset != "" && textual(mediaType) {
+		var err error
+		if body, err = utils.ToUTF8(body, charset); err != nil {
+			return err
+		}
+		// Content-Type 的 charset 优先于 xml 声明的 encoding
+		body = utils.XmlDeclareUTF8(body)
+	}
+
+	codec := resp.Codec
+	switch {
+	case codec != nil:
+	case resp.ResultType == Default:
+		if codec = codecs.byMedia(mediaType); codec == nil {
+			if strings.HasPrefix(mediaType, "text/") {
+				decodeText(body, v)
+			}
+			return nil
+		}
+	default:
+		if codec = codecs.byType(resp.ResultType); codec == nil {
+			return fmt.Errorf("sgh: no codec registered for result type %v", resp.ResultType)
+		}
+	}
+	return codec.Unmarshal(body, v)
+}
+
+// textual 判断 mediaType 是否为需要按 charset 转码的文本或 xml 类型。
+func textual(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+func decodeText(body []byte, v interface{}) {
+	switch p := v.(type) {
+	case *string:
+		*p = string(body)
+	case *[]byte:
+		*p = append([]byte(nil), body...)
+	}
+}
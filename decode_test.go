@@ -0,0 +1,73 @@
+package sgh
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func Test_decodeBody(t *testing.T) {
+	type item struct {
+		Name string `json:"name" xml:"name" url:"name"`
+	}
+	newString := func() interface{} { return new(string) }
+	newBytes := func() interface{} { return new([]byte) }
+	newItem := func() interface{} { return &item{Name: "untouched"} }
+
+	tests := []struct {
+		name        string
+		contentType string
+		resultType  BodyType
+		body        string
+		result      func() interface{}
+		want        interface{}
+		wantErr     bool
+	}{
+		{name: "json with charset.", contentType: "application/json; charset=utf-8", body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "upper case json.", contentType: "Application/JSON", body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "problem json.", contentType: "application/problem+json", body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "vnd api json.", contentType: "application/vnd.api+json", body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "text xml.", contentType: "text/xml", body: `<item><name>a</name></item>`, result: newItem, want: &item{Name: "a"}},
+		{name: "atom xml.", contentType: "application/atom+xml", body: `<item><name>a</name></item>`, result: newItem, want: &item{Name: "a"}},
+		{
+			name:        "latin1 xml by content type.",
+			contentType: "text/xml; charset=ISO-8859-1",
+			body:        "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><item><name>caf\xe9</name></item>",
+			result:      newItem,
+			want:        &item{Name: "café"},
+		},
+		{
+			name:        "latin1 xml by declaration.",
+			contentType: "application/xml",
+			body:        "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><item><name>caf\xe9</name></item>",
+			result:      newItem,
+			want:        &item{Name: "café"},
+		},
+		{name: "form.", contentType: "application/x-www-form-urlencoded", body: `name=a`, result: newItem, want: &item{Name: "a"}},
+		{name: "text to string.", contentType: "text/plain; charset=iso-8859-1", body: "caf\xe9", result: newString, want: func() *string { s := "café"; return &s }()},
+		{name: "text to bytes.", contentType: "text/csv", body: "a,b", result: newBytes, want: &[]byte{'a', ',', 'b'}},
+		{name: "text to struct untouched.", contentType: "text/plain", body: `{"name":"a"}`, result: newItem, want: &item{Name: "untouched"}},
+		{name: "unknown untouched.", contentType: "application/octet-stream", body: `{"name":"a"}`, result: newItem, want: &item{Name: "untouched"}},
+		{name: "missing content type untouched.", body: `{"name":"a"}`, result: newItem, want: &item{Name: "untouched"}},
+		{name: "explicit json.", contentType: "text/plain", resultType: Json, body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "json ignores charset.", contentType: "application/json; charset=gbk", body: `{"name":"a"}`, result: newItem, want: &item{Name: "a"}},
+		{name: "xml suffix with charset.", contentType: "application/atom+xml; charset=ISO-8859-1", body: "<item><name>caf\xe9</name></item>", result: newItem, want: &item{Name: "café"}},
+		{name: "unsupported charset.", contentType: "text/plain; charset=gbk", body: "a", result: newString, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{Header: http.Header{}, ResultType: tt.resultType, RawBody: []byte(tt.body)}
+			if tt.contentType != "" {
+				resp.Header.Set("Content-Type", tt.contentType)
+			}
+			got := tt.result()
+			err := decodeBody(resp, got, defaultCodecs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package sgh
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DownloadTo 将响应体流式写入 path，resp 可为 nil，通过 resp.Progress 获取进度。
+// path 已存在时使用 Range 从文件末尾继续下载，服务端不支持 Range 或本地文件与远端不一致时重新下载。
+// 需要客户端使用支持流式响应的 Transport，如 HTTPTransport。请求失败时不会创建文件。
+func (req *Request) DownloadTo(path string, resp *Response, hooks ...Hook) error {
+	if resp == nil {
+		resp = &Response{}
+	}
+	resp.Stream = true
+
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if req.Header.Get("Range") != "" {
+		offset = 0
+	}
+
+	if err := req.rangeRequest(offset).Do(resp, hooks...); err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Close()
+		// 416 的 Content-Range 为 bytes */size，与本地大小一致时表示文件已下载完整
+		if size, ok := parseUnsatisfiedRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			return nil
+		}
+		offset = 0
+		if err := req.Do(resp, hooks...); err != nil {
+			return err
+		}
+	}
+	defer resp.Close()
+
+	total := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("sgh: unexpected Content-Range %q, want start %d", resp.Header.Get("Content-Range"), offset)
+		}
+		total = size
+	} else {
+		offset = 0
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = resp.writeTo(f, offset, total)
+	return err
+}
+
+// rangeRequest 返回从 offset 开始下载的请求副本，offset 为 0 时返回 req 本身。
+func (req *Request) rangeRequest(offset int64) *Request {
+	if offset == 0 {
+		return req
+	}
+	// 不修改调用方的 Request
+	r := *req
+	r.Header = req.Header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	policy := req.StatusPolicy
+	if policy == nil {
+		policy = Status2xx
+	}
+	// 416 由 DownloadTo 判断文件是否已完整
+	r.StatusPolicy = func(statusCode int) bool {
+		return statusCode == http.StatusRequestedRangeNotSatisfiable || policy(statusCode)
+	}
+	return &r
+}
+
+// parseContentRange 解析 "bytes start-end/size"，size 未知时为 -1。
+func parseContentRange(value string) (start, size int64, ok bool) {
+	value = strings.TrimPrefix(value, "bytes ")
+	i, j := strings.Index(value, "-"), strings.Index(value, "/")
+	if i < 0 || j < i {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(value[:i], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size = -1
+	if value[j+1:] != "*" {
+		if size, err = strconv.ParseInt(value[j+1:], 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, size, true
+}
+
+// parseUnsatisfiedRange 解析 416 响应的 "bytes */size"。
+func parseUnsatisfiedRange(value string) (size int64, ok bool) {
+	if !strings.HasPrefix(value, "bytes */") {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimPrefix(value, "bytes */"), 10, 64)
+	return size, err == nil
+}
@@ -0,0 +1,195 @@
+package sgh
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSimpleClient_Do_Stream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("bad"))
+			return
+		}
+		w.Write([]byte("hello"))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(" world"))
+	}))
+	defer srv.Close()
+
+	// 默认的 FastTransport 不支持流式响应，不发起请求直接返回错误，也不会重试
+	fast := &countTransport{Transport: NewSimpleClient().transport}
+	err := NewSimpleClient(WithTransport(fast), WithRetryPolicy(DefaultRetryPolicy())).Do(NewRequest().Get(srv.URL), NewStreamResponse())
+	if !errors.Is(err, ErrStreamNotSupported) || fast.calls != 1 {
+		t.Errorf("SimpleClient.Do() error = %v after %d calls, want %v after 1 call", err, fast.calls, ErrStreamNotSupported)
+	}
+
+	sc := NewSimpleClient(WithTransport(NewHTTPTransport(nil)), WithTimeout(time.Second))
+
+	resp := NewStreamResponse()
+	if err := sc.Do(NewRequest().Get(srv.URL), resp); err != nil {
+		t.Fatalf("SimpleClient.Do() error = %v", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Close()
+	if err != nil || string(body) != "hello world" {
+		t.Errorf("Response.Body = %q, %v, want %q", body, err, "hello world")
+	}
+
+	resp = NewStreamResponse()
+	err = sc.Do(NewRequest().Get(srv.URL+"/error"), resp)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || string(httpErr.Body) != "bad" {
+		t.Errorf("SimpleClient.Do() error = %v, want HTTPError with body", err)
+	}
+	if resp.Body != nil {
+		t.Errorf("Response.Body should be closed on error")
+	}
+}
+
+// countTransport 记录 RoundTrip 的调用次数。
+type countTransport struct {
+	Transport
+	calls int
+}
+
+func (t *countTransport) RoundTrip(call *Call, resp *Response) error {
+	t.calls++
+	return t.Transport.RoundTrip(call, resp)
+}
+
+func TestRequest_DownloadTo(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/notfound" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/norange" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write(content)
+			return
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		exist     []byte
+		wantFirst int64
+		wantErr   bool
+	}{
+		{name: "new file.", path: "/", wantFirst: 0},
+		{name: "resume.", path: "/", exist: content[:4000], wantFirst: 4000},
+		{name: "already complete.", path: "/", exist: content, wantFirst: -1},
+		{name: "server without range.", path: "/norange", exist: []byte("stale"), wantFirst: 0},
+		{name: "local file larger than remote.", path: "/", exist: append(append([]byte(nil), content...), "stale"...), wantFirst: 0},
+		{name: "request failed.", path: "/notfound", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "sgh")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "file")
+			if tt.exist != nil {
+				if err := ioutil.WriteFile(path, tt.exist, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			first, last, total := int64(-1), int64(0), int64(0)
+			resp := NewStreamResponse().SetProgress(func(written, t int64) {
+				if first < 0 {
+					first = written
+				}
+				last, total = written, t
+			})
+			req := NewRequest().Get(srv.URL + tt.path).Client(NewSimpleClient(WithTransport(NewHTTPTransport(nil))))
+			if err := req.DownloadTo(path, resp); (err != nil) != tt.wantErr {
+				t.Fatalf("Request.DownloadTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("file created for failed request, stat error = %v", err)
+				}
+				return
+			}
+			if req.Header != nil || req.StatusPolicy != nil {
+				t.Errorf("Request modified by DownloadTo")
+			}
+			got, _ := ioutil.ReadFile(path)
+			if !bytes.Equal(got, content) {
+				t.Errorf("downloaded %d bytes, want %d", len(got), len(content))
+			}
+			if tt.wantFirst < 0 {
+				if first >= 0 {
+					t.Errorf("progress called for complete file")
+				}
+				return
+			}
+			if first <= tt.wantFirst || first > tt.wantFirst+int64(len(content)) {
+				t.Errorf("first progress = %d, want after %d", first, tt.wantFirst)
+			}
+			if last != int64(len(content)) || total != int64(len(content)) {
+				t.Errorf("last progress = %d/%d, want %d", last, total, len(content))
+			}
+		})
+	}
+}
+
+func Test_parseContentRange(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantStart int64
+		wantSize  int64
+		wantOk    bool
+	}{
+		{value: "bytes 100-199/1000", wantStart: 100, wantSize: 1000, wantOk: true},
+		{value: "bytes 100-199/*", wantStart: 100, wantSize: -1, wantOk: true},
+		{value: "bytes */1000", wantOk: false},
+		{value: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, size, ok := parseContentRange(tt.value)
+			if start != tt.wantStart || size != tt.wantSize || ok != tt.wantOk {
+				t.Errorf("parseContentRange() = %v, %v, %v, want %v, %v, %v", start, size, ok, tt.wantStart, tt.wantSize, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_parseUnsatisfiedRange(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantSize int64
+		wantOk   bool
+	}{
+		{value: "bytes */1000", wantSize: 1000, wantOk: true},
+		{value: "bytes 100-199/1000", wantOk: false},
+		{value: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			size, ok := parseUnsatisfiedRange(tt.value)
+			if size != tt.wantSize || ok != tt.wantOk {
+				t.Errorf("parseUnsatisfiedRange() = %v, %v, want %v, %v", size, ok, tt.wantSize, tt.wantOk)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package sgh
 
+import "strconv"
+
 type HttpMethod uint8
 
 func (hm HttpMethod) String() string {
@@ -41,9 +43,47 @@ const (
 
 type BodyType uint8
 
+func (bt BodyType) String() string {
+	switch bt {
+	case Default:
+		return "Default"
+	case Json:
+		return "Json"
+	case Xml:
+		return "Xml"
+	case UrlQuery:
+		return "UrlQuery"
+	case Form:
+		return "Form"
+	case Multipart:
+		return "Multipart"
+	case Protobuf:
+		return "Protobuf"
+	case ProtoJson:
+		return "ProtoJson"
+	case Msgpack:
+		return "Msgpack"
+	case Cbor:
+		return "Cbor"
+	}
+	return "BodyType(" + strconv.Itoa(int(bt)) + ")"
+}
+
 const (
 	Default BodyType = iota
 	Json
 	Xml
 	UrlQuery
+	// application/x-www-form-urlencoded
+	Form
+	// multipart/form-data
+	Multipart
+	// application/x-protobuf，Body 与 Result 须为 proto.Message
+	Protobuf
+	// protobuf 的 JSON 映射，Body 与 Result 须为 proto.Message
+	ProtoJson
+	// application/msgpack
+	Msgpack
+	// application/cbor
+	Cbor
 )
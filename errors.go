@@ -0,0 +1,91 @@
+package sgh
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// ErrMissingPathParam 表示 URL 中存在未通过 Request.PathParam 填充的占位符。
+var ErrMissingPathParam = errors.New("sgh: missing path param")
+
+// ErrBodyNotRewindable 表示请求需要重试，但流式请求体已被读取且无法重新获取。
+var ErrBodyNotRewindable = errors.New("sgh: retry skipped, request body is not rewindable")
+
+// ErrStreamNotSupported 表示 Transport 无法流式读取响应体，如默认的 FastTransport，
+// 流式响应与 DownloadTo 需要通过 WithTransport(NewHTTPTransport(nil)) 切换。
+var ErrStreamNotSupported = errors.New("sgh: transport does not support stream response")
+
+// EncodeError 表示请求体或查询参数编码失败，请求不会被发送。
+type EncodeError struct {
+	BodyType BodyType
+	Type     reflect.Type // 编码失败的值的类型
+	Err      error
+}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("sgh: encode %v as %v: %v", e.Type, e.BodyType, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+func newEncodeError(bt BodyType, v interface{}, err error) *EncodeError {
+	return &EncodeError{BodyType: bt, Type: reflect.TypeOf(v), Err: err}
+}
+
+// ContextError 表示请求因 Request.Ctx 被取消或超过 deadline 而中止。
+type ContextError struct {
+	Err error
+}
+
+func (e *ContextError) Error() string {
+	return "sgh: request aborted: " + e.Err.Error()
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// StatusPolicy 判断响应状态码是否视为成功。
+type StatusPolicy func(statusCode int) bool
+
+// Status2xx 是 SimpleClient 的默认 StatusPolicy。
+func Status2xx(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// AnyStatus 接受任意状态码。
+func AnyStatus(int) bool {
+	return true
+}
+
+// HTTPError 在响应状态码未通过 StatusPolicy 时返回。
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	// Result 即 Response.ErrorResult，已按响应格式解析
+	Result interface{}
+	// DecodeErr 为解析 Result 时的错误
+	DecodeErr error
+}
+
+func newHTTPError(resp *Response, codecs *codecRegistry) *HTTPError {
+	err := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       resp.RawBody,
+		Result:     resp.ErrorResult,
+	}
+	err.DecodeErr = decodeBody(resp, resp.ErrorResult, codecs)
+	return err
+}
+
+func (e *HTTPError) Error() string {
+	return "sgh: unexpected status " + e.Status
+}
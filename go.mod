@@ -2,4 +2,9 @@ module github.com/SmallTianTian/simple-go-http
 
 go 1.14
 
-require github.com/valyala/fasthttp v1.34.0
+require (
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/valyala/fasthttp v1.34.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	google.golang.org/protobuf v1.30.0
+)
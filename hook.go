@@ -0,0 +1,51 @@
+package sgh
+
+// Hook 是可注册到 SimpleClient 或随 Do 传入的钩子，
+// 具体类型为 BeforeRequest、AfterResponse 或 OnError，同类钩子按注册顺序执行。
+type Hook interface {
+	hook()
+}
+
+// BeforeRequest 在请求构建前执行，返回 error 将中止请求。
+type BeforeRequest func(req *Request) error
+
+// AfterResponse 在响应成功解析后执行，此时 Response 的状态码、RawBody 与 Result 均已填充。
+// 返回 error 将跳过后续钩子并作为 Do 的返回值。
+type AfterResponse func(req *Request, resp *Response) error
+
+// OnError 在 Do 返回 error 前执行。
+type OnError func(req *Request, resp *Response, err error)
+
+func (BeforeRequest) hook() {}
+func (AfterResponse) hook() {}
+func (OnError) hook()       {}
+
+func runBeforeRequest(hooks []Hook, req *Request) error {
+	for _, h := range hooks {
+		if f, ok := h.(BeforeRequest); ok {
+			if err := f(req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runAfterResponse(hooks []Hook, req *Request, resp *Response) error {
+	for _, h := range hooks {
+		if f, ok := h.(AfterResponse); ok {
+			if err := f(req, resp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runOnError(hooks []Hook, req *Request, resp *Response, err error) {
+	for _, h := range hooks {
+		if f, ok := h.(OnError); ok {
+			f(req, resp, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package sgh
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSimpleClient_Do_Hooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(`{"key":"value"}`))
+	}))
+	defer srv.Close()
+
+	errAbort := errors.New("abort")
+	var calls []string
+	record := func(name string) []Hook {
+		return []Hook{
+			BeforeRequest(func(req *Request) error {
+				calls = append(calls, name+".before")
+				return nil
+			}),
+			AfterResponse(func(req *Request, resp *Response) error {
+				calls = append(calls, name+".after:"+resp.Status+":"+string(resp.RawBody))
+				return nil
+			}),
+			OnError(func(req *Request, resp *Response, err error) {
+				calls = append(calls, name+".error")
+			}),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		clientHooks []Hook
+		callHooks   []Hook
+		wantErr     error
+		wantCalls   []string
+	}{
+		{
+			name:        "client hooks run before call hooks.",
+			url:         srv.URL,
+			clientHooks: record("client"),
+			callHooks:   record("call"),
+			wantCalls: []string{
+				"client.before",
+				"call.before",
+				`client.after:200 OK:{"key":"value"}`,
+				`call.after:200 OK:{"key":"value"}`,
+			},
+		},
+		{
+			name:        "before hook short-circuit.",
+			url:         srv.URL,
+			clientHooks: append([]Hook{BeforeRequest(func(*Request) error { return errAbort })}, record("client")...),
+			callHooks:   record("call"),
+			wantErr:     errAbort,
+			wantCalls:   []string{"client.error", "call.error"},
+		},
+		{
+			name:        "after hook short-circuit.",
+			url:         srv.URL,
+			clientHooks: record("client"),
+			callHooks: []Hook{
+				AfterResponse(func(*Request, *Response) error { return errAbort }),
+				AfterResponse(func(*Request, *Response) error {
+					calls = append(calls, "unreachable")
+					return nil
+				}),
+			},
+			wantErr: errAbort,
+			wantCalls: []string{
+				"client.before",
+				`client.after:200 OK:{"key":"value"}`,
+				"client.error",
+			},
+		},
+		{
+			name:        "http error run on error hooks.",
+			url:         srv.URL + "?fail=1",
+			clientHooks: record("client"),
+			wantCalls:   []string{"client.before", "client.error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			sc := NewSimpleClient()
+			sc.AddHooks(tt.clientHooks...)
+
+			var rs map[string]string
+			err := sc.Do(NewRequest().Get(tt.url), NewJsonResponse(&rs), tt.callHooks...)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("SimpleClient.Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("hook calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -1,16 +1,24 @@
 package sgh
 
-import "time"
-
+// SimpleHttp 是发送 Request 的客户端，SimpleClient 与 sghtest.Client 均实现了该接口。
 type SimpleHttp interface {
-	Do(*Request, *Response) error
-	SetTimeout(time.Duration)
+	Do(req *Request, resp *Response, hooks ...Hook) error
+}
+
+var _ SimpleHttp = (*SimpleClient)(nil)
+
+// SetDefaultClient 替换 Request.Do 默认使用的客户端。
+func SetDefaultClient(client SimpleHttp) {
+	defaultClient = client
 }
 
 var (
 	debug bool
 )
 
+// OpenDebug 使所有客户端将请求与响应记录到 stderr。
+//
+// Deprecated: 使用 WithDebug 为客户端开启，或使用 Request.SetDebug 为单个请求开启，输出位置通过 WithLogger 设置。
 func OpenDebug() {
 	debug = true
 }
@@ -0,0 +1,148 @@
+package sgh
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger 记录请求与响应，args 为交替出现的键值对。
+// *slog.Logger 满足该接口，可以直接使用 WithLogger(slog.Default())。
+type Logger interface {
+	Info(msg string, args ...interface{})
+}
+
+// LoggerFunc 将签名相同的函数适配为 Logger，如 zap 的 SugaredLogger.Infow。
+type LoggerFunc func(msg string, args ...interface{})
+
+func (f LoggerFunc) Info(msg string, args ...interface{}) {
+	f(msg, args...)
+}
+
+// NewLogger 返回以 key=value 格式逐行写入 w 的 Logger。
+func NewLogger(w io.Writer) Logger {
+	return &textLogger{w: w}
+}
+
+type textLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *textLogger) Info(msg string, args ...interface{}) {
+	var sb strings.Builder
+	sb.WriteString(time.Now().Format(time.RFC3339))
+	sb.WriteString(" ")
+	sb.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		sb.WriteString(" ")
+		sb.WriteString(fmt.Sprint(args[i]))
+		sb.WriteString("=")
+		sb.WriteString(formatLogValue(args[i+1]))
+	}
+	sb.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, sb.String())
+}
+
+func formatLogValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case http.Header:
+		// 按键名排序，保留每个键的全部值
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+":"+strings.Join(v[k], ","))
+		}
+		s = "{" + strings.Join(parts, " ") + "}"
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \"=\n\t") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// defaultLogger 在客户端未设置 Logger 时使用，写入 stderr。
+var defaultLogger Logger = NewLogger(os.Stderr)
+
+const defaultLogBodyLimit = 1024
+
+// logMiddleware 记录每次实际发送的请求与收到的响应，位于重试中间件之内。
+// 是否记录由 OpenDebug、WithDebug 与 Request.Debug 依次覆盖决定。
+func (sc *SimpleClient) logMiddleware(next Handler) Handler {
+	return func(call *Call, resp *Response) error {
+		enabled := debug || sc.debug
+		if call.Request != nil && call.Request.Debug != nil {
+			enabled = *call.Request.Debug
+		}
+		if !enabled {
+			return next(call, resp)
+		}
+		logger := sc.logger
+		if logger == nil {
+			logger = defaultLogger
+		}
+
+		redactor := sc.redactor
+		reqBody := "<stream body>"
+		if call.BodyStream == nil {
+			reqBody = truncateBody(redactor.body(call.Header.Get("Content-Type"), call.Body), sc.logBodyLimit)
+		}
+		logger.Info("sgh request",
+			"method", call.Method.String(),
+			"url", call.URL,
+			"header", redactor.header(call.Header),
+			"body", reqBody,
+		)
+
+		start := time.Now()
+		err := next(call, resp)
+		latency := time.Since(start)
+		if err != nil {
+			logger.Info("sgh request failed",
+				"method", call.Method.String(),
+				"url", call.URL,
+				"latency", latency,
+				"error", err,
+			)
+			return err
+		}
+
+		respBody := "<stream body>"
+		if resp.Body == nil {
+			respBody = truncateBody(redactor.body(resp.Header.Get("Content-Type"), resp.RawBody), sc.logBodyLimit)
+		}
+		logger.Info("sgh response",
+			"method", call.Method.String(),
+			"url", call.URL,
+			"status", resp.StatusCode,
+			"latency", latency,
+			"header", redactor.header(resp.Header),
+			"body", respBody,
+		)
+		return nil
+	}
+}
+
+// truncateBody 截断超过 limit 字节的内容，limit 小于 0 时不截断。
+func truncateBody(body []byte, limit int) string {
+	if limit < 0 || len(body) <= limit {
+		return string(body)
+	}
+	return fmt.Sprintf("%s...(%d bytes truncated)", body[:limit], len(body)-limit)
+}
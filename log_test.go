@@ -0,0 +1,131 @@
+package sgh
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type logRecord struct {
+	msg  string
+	args map[string]interface{}
+}
+
+type recordLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordLogger) Info(msg string, args ...interface{}) {
+	r := logRecord{msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		r.args[args[i].(string)] = args[i+1]
+	}
+	l.mu.Lock()
+	l.records = append(l.records, r)
+	l.mu.Unlock()
+}
+
+func TestSimpleClient_Do_Logger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("response body"))
+	}))
+	defer srv.Close()
+
+	logger := &recordLogger{}
+	sc := NewSimpleClient(WithLogger(logger), WithDebug(true), WithLogBodyLimit(4))
+	req := NewRequest().Post(srv.URL, map[string]int{"a": 1}).SetHeader("X-Req", "1")
+	if err := sc.Do(req, nil); err != nil {
+		t.Fatalf("SimpleClient.Do() error = %v", err)
+	}
+
+	if len(logger.records) != 2 {
+		t.Fatalf("records = %v, want request and response", logger.records)
+	}
+	reqLog, respLog := logger.records[0], logger.records[1]
+	if reqLog.msg != "sgh request" || reqLog.args["method"] != "POST" || reqLog.args["url"] != srv.URL {
+		t.Errorf("request record = %v", reqLog)
+	}
+	if got := reqLog.args["header"].(http.Header).Get("X-Req"); got != "1" {
+		t.Errorf("request header X-Req = %v, want 1", got)
+	}
+	if got := reqLog.args["body"]; got != `{"a"...(3 bytes truncated)` {
+		t.Errorf("request body = %v", got)
+	}
+	if respLog.msg != "sgh response" || respLog.args["status"] != http.StatusCreated {
+		t.Errorf("response record = %v", respLog)
+	}
+	if got := respLog.args["header"].(http.Header)["X-Multi"]; len(got) != 2 {
+		t.Errorf("response header X-Multi = %v, want all values", got)
+	}
+	if got := respLog.args["body"]; got != "resp...(9 bytes truncated)" {
+		t.Errorf("response body = %v", got)
+	}
+	if _, ok := respLog.args["latency"].(time.Duration); !ok {
+		t.Errorf("response latency = %v", respLog.args["latency"])
+	}
+}
+
+func TestRequest_SetDebug(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	stderr := &recordLogger{}
+	defer func(l Logger) { defaultLogger = l }(defaultLogger)
+	defaultLogger = stderr
+
+	tests := []struct {
+		name       string
+		withLogger bool
+		opts       []Option
+		req        *Request
+		want       int
+	}{
+		{name: "off by default.", req: NewRequest().Get(srv.URL), want: 0},
+		{name: "request debug to stderr.", req: NewRequest().Get(srv.URL).SetDebug(true), want: 2},
+		{name: "logger alone not enable.", withLogger: true, req: NewRequest().Get(srv.URL), want: 0},
+		{name: "request debug to client logger.", withLogger: true, req: NewRequest().Get(srv.URL).SetDebug(true), want: 2},
+		{name: "client debug.", withLogger: true, opts: []Option{WithDebug(true)}, req: NewRequest().Get(srv.URL), want: 2},
+		{name: "request override client debug.", withLogger: true, opts: []Option{WithDebug(true)}, req: NewRequest().Get(srv.URL).SetDebug(false), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr.records = nil
+			logger := stderr
+			opts := tt.opts
+			if tt.withLogger {
+				logger = &recordLogger{}
+				opts = append(opts, WithLogger(logger))
+			}
+			if err := NewSimpleClient(opts...).Do(tt.req, nil); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if len(logger.records) != tt.want {
+				t.Errorf("records = %v, want %d", logger.records, tt.want)
+			}
+			if tt.withLogger && len(stderr.records) != 0 {
+				t.Errorf("stderr records = %v, want none with client logger", stderr.records)
+			}
+		})
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	NewLogger(&buf).Info("sgh request",
+		"method", "GET",
+		"header", http.Header{"B": {"2"}, "A": {"1", "x y"}},
+		"body", "",
+	)
+	want := ` sgh request method=GET header="{A:1,x y B:2}" body=""` + "\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("NewLogger() output = %q, want suffix %q", got, want)
+	}
+}
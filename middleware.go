@@ -0,0 +1,46 @@
+package sgh
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Call 是由 Request 构建出的一次实际 http 调用，在中间件链中传递。
+type Call struct {
+	Ctx     context.Context
+	Request *Request
+	Method  HttpMethod
+	URL     string
+	Header  http.Header
+	Body    []byte
+	// BodyStream 不为 nil 时代替 Body 作为请求体，BodySize 为 -1 表示长度未知，使用 chunked 编码
+	BodyStream io.Reader
+	BodySize   int64
+	// GetBody 返回一个新的 BodyStream 用于重试，为 nil 时 BodyStream 无法重试
+	GetBody func() (io.Reader, error)
+	// 单次请求的超时时间，已合并 SimpleClient 与 Request 的设置
+	Timeout time.Duration
+}
+
+// Handler 发起 call 并填充 resp 的状态码、响应头与 RawBody。
+// 响应体到 Result 的解析在整个中间件链完成后进行。
+type Handler func(call *Call, resp *Response) error
+
+// Middleware 包裹下一层 Handler，可以决定是否以及如何调用 next。
+type Middleware func(next Handler) Handler
+
+// Use 追加中间件。
+// 中间件按注册顺序由外向内执行，内置的重试与日志中间件位于所有用户中间件之内、实际请求之外。
+func (sc *SimpleClient) Use(middlewares ...Middleware) {
+	sc.middlewares = append(sc.middlewares, middlewares...)
+}
+
+func (sc *SimpleClient) handler() Handler {
+	h := retryMiddleware(sc.retry)(sc.logMiddleware(sc.transport.RoundTrip))
+	for i := len(sc.middlewares) - 1; i >= 0; i-- {
+		h = sc.middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,122 @@
+package sgh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSimpleClient_Use(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		if r.URL.Query().Get("flaky") != "" && n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"signature":"` + r.Header.Get("X-Signature") + `"}`))
+	}))
+	defer srv.Close()
+
+	var trace []string
+	tracing := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(call *Call, resp *Response) error {
+				trace = append(trace, name+".in")
+				err := next(call, resp)
+				trace = append(trace, name+".out")
+				return err
+			}
+		}
+	}
+	signing := func(next Handler) Handler {
+		return func(call *Call, resp *Response) error {
+			call.Header.Set("X-Signature", call.Method.String()+" "+call.URL)
+			return next(call, resp)
+		}
+	}
+	caching := func(next Handler) Handler {
+		return func(call *Call, resp *Response) error {
+			trace = append(trace, "cache.hit")
+			resp.StatusCode = http.StatusOK
+			resp.Header = http.Header{"Content-Type": []string{"application/json"}}
+			resp.RawBody = []byte(`{"signature":"cached"}`)
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		middlewares []Middleware
+		retry       *RetryPolicy
+		wantTrace   []string
+		wantHits    int32
+		wantResult  map[string]string
+	}{
+		{
+			name:        "middlewares run in registration order.",
+			url:         srv.URL,
+			middlewares: []Middleware{tracing("first"), tracing("second")},
+			wantTrace:   []string{"first.in", "second.in", "second.out", "first.out"},
+			wantHits:    1,
+			wantResult:  map[string]string{"signature": ""},
+		},
+		{
+			name:        "middleware can modify the call.",
+			url:         srv.URL,
+			middlewares: []Middleware{signing},
+			wantHits:    1,
+			wantResult:  map[string]string{"signature": "GET " + srv.URL},
+		},
+		{
+			name:        "middleware can short-circuit.",
+			url:         srv.URL,
+			middlewares: []Middleware{tracing("first"), caching, tracing("unreachable")},
+			wantTrace:   []string{"first.in", "cache.hit", "first.out"},
+			wantHits:    0,
+			wantResult:  map[string]string{"signature": "cached"},
+		},
+		{
+			name:        "retry runs inside user middlewares.",
+			url:         srv.URL + "?flaky=1",
+			middlewares: []Middleware{tracing("first")},
+			retry: &RetryPolicy{
+				MaxAttempts: 2,
+				MinBackoff:  time.Millisecond,
+				RetryStatus: []int{http.StatusServiceUnavailable},
+			},
+			wantTrace:  []string{"first.in", "first.out"},
+			wantHits:   2,
+			wantResult: map[string]string{"signature": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace = nil
+			atomic.StoreInt32(&hits, 0)
+
+			sc := NewSimpleClient()
+			sc.SetRetryPolicy(tt.retry)
+			sc.Use(tt.middlewares...)
+
+			var rs map[string]string
+			if err := sc.Do(NewRequest().Get(tt.url), NewDefaultResponse(&rs)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if !reflect.DeepEqual(trace, tt.wantTrace) {
+				t.Errorf("middleware trace = %v, want %v", trace, tt.wantTrace)
+			}
+			if got := atomic.LoadInt32(&hits); got != tt.wantHits {
+				t.Errorf("server hits = %v, want %v", got, tt.wantHits)
+			}
+			if !reflect.DeepEqual(rs, tt.wantResult) {
+				t.Errorf("Response.Result = %v, want %v", rs, tt.wantResult)
+			}
+		})
+	}
+}
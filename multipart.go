@@ -0,0 +1,101 @@
+package sgh
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MultipartPart 是 multipart/form-data 请求体中的一个部分。
+type MultipartPart struct {
+	Header textproto.MIMEHeader
+	Reader io.Reader
+}
+
+// FormField 添加一个普通字段。
+func (req *Request) FormField(key, value string) *Request {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(key)))
+	return req.FormPart(header, strings.NewReader(value))
+}
+
+// FormFile 添加一个文件，文件内容在发送时从 r 中流式读取。
+func (req *Request) FormFile(field, filename string, r io.Reader) *Request {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition",
+		fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field), escapeQuotes(filename)))
+	header.Set("Content-Type", "application/octet-stream")
+	return req.FormPart(header, r)
+}
+
+// FormPart 添加一个自定义头部的部分，可用于指定文件的 Content-Type 等。
+func (req *Request) FormPart(header textproto.MIMEHeader, r io.Reader) *Request {
+	req.Parts = append(req.Parts, &MultipartPart{Header: header, Reader: r})
+	return req
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// multipartBody 在首次读取时才开始编码，编码过程通过 io.Pipe 流式输出，不会将文件完整读入内存。
+type multipartBody struct {
+	once   sync.Once
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+	mw     *multipart.Writer
+	fields url.Values
+	parts  []*MultipartPart
+}
+
+func newMultipartBody(fields url.Values, parts []*MultipartPart) (*multipartBody, string) {
+	pr, pw := io.Pipe()
+	b := &multipartBody{pr: pr, pw: pw, mw: multipart.NewWriter(pw), fields: fields, parts: parts}
+	return b, b.mw.FormDataContentType()
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() { b.pw.CloseWithError(b.write()) }()
+	})
+	return b.pr.Read(p)
+}
+
+// Close 中止尚未完成的编码。
+func (b *multipartBody) Close() error {
+	return b.pr.Close()
+}
+
+func (b *multipartBody) write() error {
+	keys := make([]string, 0, len(b.fields))
+	for k := range b.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range b.fields[k] {
+			if err := b.mw.WriteField(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	for _, part := range b.parts {
+		w, err := b.mw.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		if part.Reader != nil {
+			if _, err := io.Copy(w, part.Reader); err != nil {
+				return err
+			}
+		}
+	}
+	return b.mw.Close()
+}
@@ -0,0 +1,117 @@
+package sgh
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
+)
+
+type repeatReader byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestSimpleClient_Do_Multipart(t *testing.T) {
+	const fileSize = 4 << 20
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		form := r.MultipartForm
+		result := map[string]interface{}{
+			"name":  form.Value["name"],
+			"page":  form.Value["page"],
+			"files": len(form.File["file"]),
+		}
+		if fhs := form.File["file"]; len(fhs) == 1 {
+			f, _ := fhs[0].Open()
+			body, _ := ioutil.ReadAll(f)
+			f.Close()
+			result["filename"] = fhs[0].Filename
+			result["size"] = len(body)
+			result["valid"] = bytes.Count(body, []byte{'x'}) == len(body)
+		}
+		if fhs := form.File["meta"]; len(fhs) == 1 {
+			result["meta_type"] = fhs[0].Header.Get("Content-Type")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer srv.Close()
+
+	transports := map[string]Transport{
+		"fasthttp": NewSimpleClient().transport,
+		"net/http": NewHTTPTransport(nil),
+	}
+	for name, transport := range transports {
+		t.Run(name, func(t *testing.T) {
+			sc := NewSimpleClient()
+			sc.SetTransport(transport)
+
+			metaHeader := textproto.MIMEHeader{}
+			metaHeader.Set("Content-Disposition", `form-data; name="meta"; filename="meta.json"`)
+			metaHeader.Set("Content-Type", "application/json")
+
+			var rs struct {
+				Name     []string `json:"name"`
+				Page     []string `json:"page"`
+				Files    int      `json:"files"`
+				Filename string   `json:"filename"`
+				Size     int      `json:"size"`
+				Valid    bool     `json:"valid"`
+				MetaType string   `json:"meta_type"`
+			}
+			req := NewRequest().
+				HttpMethod(POST).
+				Url(srv.URL).
+				HttpBody(map[string]int{"page": 1}).
+				FormField("name", "tian").
+				FormFile("file", `a "b".txt`, io.LimitReader(repeatReader('x'), fileSize)).
+				FormPart(metaHeader, strings.NewReader(`{}`))
+			if err := sc.Do(req, NewJsonResponse(&rs)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if len(rs.Name) != 1 || rs.Name[0] != "tian" || len(rs.Page) != 1 || rs.Page[0] != "1" {
+				t.Errorf("form values = %v %v", rs.Name, rs.Page)
+			}
+			if rs.Files != 1 || rs.Filename != `a "b".txt` || rs.Size != fileSize || !rs.Valid {
+				t.Errorf("form file = %+v", rs)
+			}
+			if rs.MetaType != "application/json" {
+				t.Errorf("part content type = %v, want application/json", rs.MetaType)
+			}
+		})
+	}
+}
+
+func TestRequest_build_Multipart(t *testing.T) {
+	call, err := NewRequest().Post("https://example.com", nil).FormField("k", "v").build(defaultCodecs, utils.QueryOptions{})
+	if err != nil {
+		t.Fatalf("Request.build() error = %v", err)
+	}
+	if ct := call.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/form-data; boundary=") {
+		t.Errorf("Content-Type = %v, want multipart/form-data", ct)
+	}
+	if call.BodyStream == nil || call.BodySize != -1 || call.Body != nil {
+		t.Errorf("Call body = %v, stream = %v, size = %v", call.Body, call.BodyStream, call.BodySize)
+	}
+	body, _ := ioutil.ReadAll(call.BodyStream)
+	if !bytes.Contains(body, []byte(`name="k"`)) {
+		t.Errorf("multipart body = %s", body)
+	}
+}
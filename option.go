@@ -0,0 +1,144 @@
+package sgh
+
+import (
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpproxy"
+)
+
+// Option 用于 NewSimpleClient 的配置。
+//
+// 连接相关的选项作用于默认的 FastTransport 所使用的 fasthttp.Client，
+// 通过 WithTransport 替换 Transport 后这些选项不再生效。
+type Option func(*SimpleClient)
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(sc *SimpleClient) { sc.timeout = timeout }
+}
+
+func WithTransport(transport Transport) Option {
+	return func(sc *SimpleClient) { sc.transport = transport }
+}
+
+func WithStatusPolicy(policy StatusPolicy) Option {
+	return func(sc *SimpleClient) { sc.statusPolicy = policy }
+}
+
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(sc *SimpleClient) { sc.retry = policy }
+}
+
+// WithBaseURL 设置基础地址，Request.URL 为相对地址时拼接在其后。
+func WithBaseURL(baseURL string) Option {
+	return func(sc *SimpleClient) { sc.baseURL = baseURL }
+}
+
+// WithHeader 设置默认请求头，Request 中已存在的请求头不会被覆盖。
+func WithHeader(key, value string) Option {
+	return func(sc *SimpleClient) { sc.header.Set(key, value) }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+func WithMaxConnsPerHost(n int) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.MaxConnsPerHost = n })
+}
+
+func WithMaxIdleConnDuration(d time.Duration) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.MaxIdleConnDuration = d })
+}
+
+func WithReadTimeout(d time.Duration) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.ReadTimeout = d })
+}
+
+func WithWriteTimeout(d time.Duration) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.WriteTimeout = d })
+}
+
+func WithMaxResponseBodySize(n int) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.MaxResponseBodySize = n })
+}
+
+func WithTLSConfig(config *tls.Config) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.TLSConfig = config })
+}
+
+func WithDial(dial fasthttp.DialFunc) Option {
+	return withFastClient(func(c *fasthttp.Client) { c.Dial = dial })
+}
+
+// WithProxy 通过代理发送请求。
+// proxy 形如 "user:pass@host:port" 时使用 http 代理，以 "socks5://" 开头时使用 socks5 代理。
+func WithProxy(proxy string) Option {
+	if strings.HasPrefix(proxy, "socks5://") {
+		return WithDial(fasthttpproxy.FasthttpSocksDialer(proxy))
+	}
+	return WithDial(fasthttpproxy.FasthttpHTTPDialer(strings.TrimPrefix(proxy, "http://")))
+}
+
+// WithLogger 设置日志的输出位置，见 SimpleClient.SetLogger。
+func WithLogger(logger Logger) Option {
+	return func(sc *SimpleClient) {
+		sc.logger = logger
+	}
+}
+
+// WithDebug 设置是否默认记录所有请求与响应，见 SimpleClient.SetDebug。
+func WithDebug(debug bool) Option {
+	return func(sc *SimpleClient) {
+		sc.debug = debug
+	}
+}
+
+// WithLogBodyLimit 设置日志中请求体与响应体的最大字节数，超出部分被截断，小于 0 表示不截断。
+func WithLogBodyLimit(limit int) Option {
+	return func(sc *SimpleClient) {
+		sc.logBodyLimit = limit
+	}
+}
+
+// WithRedactor 设置日志中敏感信息的遮盖规则，默认为 DefaultRedactor()，nil 表示不遮盖。
+func WithRedactor(redactor *Redactor) Option {
+	return func(sc *SimpleClient) {
+		sc.redactor = redactor
+	}
+}
+
+// WithCodec 为客户端注册 bt 的编解码方式，见 SimpleClient.RegisterCodec。
+func WithCodec(bt BodyType, codec Codec) Option {
+	return func(sc *SimpleClient) {
+		sc.RegisterCodec(bt, codec)
+	}
+}
+
+// WithQueryOptions 设置 QueryStruct、UrlQuery、Form 与 multipart 字段的编码选项，
+// 可被 Request.SetQueryOptions 覆盖。
+func WithQueryOptions(opts utils.QueryOptions) Option {
+	return func(sc *SimpleClient) {
+		sc.queryOptions = opts
+	}
+}
+
+// WithPreserveHeaderCase 按原样发送请求头名称，不再规范化为 Content-Type 的形式，
+// 非规范化的名称需要直接赋值，如 req.Header["x-custom-ID"] = []string{"1"}。
+// 仅对 FastTransport 生效，HTTPTransport 本身不会修改请求头名称。
+func WithPreserveHeaderCase() Option {
+	return withFastClient(func(c *fasthttp.Client) {
+		c.DisableHeaderNamesNormalizing = true
+	})
+}
+
+func withFastClient(f func(*fasthttp.Client)) Option {
+	return func(sc *SimpleClient) {
+		if t, ok := sc.transport.(*FastTransport); ok && t.Client != nil {
+			f(t.Client)
+		}
+	}
+}
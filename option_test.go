@@ -0,0 +1,132 @@
+package sgh
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNewSimpleClient_FastOptions(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+
+	tests := []struct {
+		name string
+		opt  Option
+		want *fasthttp.Client
+	}{
+		{name: "max conns per host.", opt: WithMaxConnsPerHost(10), want: &fasthttp.Client{MaxConnsPerHost: 10}},
+		{name: "max idle conn duration.", opt: WithMaxIdleConnDuration(time.Second), want: &fasthttp.Client{MaxIdleConnDuration: time.Second}},
+		{name: "read timeout.", opt: WithReadTimeout(time.Second), want: &fasthttp.Client{ReadTimeout: time.Second}},
+		{name: "write timeout.", opt: WithWriteTimeout(time.Second), want: &fasthttp.Client{WriteTimeout: time.Second}},
+		{name: "max response body size.", opt: WithMaxResponseBodySize(1024), want: &fasthttp.Client{MaxResponseBodySize: 1024}},
+		{name: "tls config.", opt: WithTLSConfig(tlsConfig), want: &fasthttp.Client{TLSConfig: tlsConfig}},
+		{name: "preserve header case.", opt: WithPreserveHeaderCase(), want: &fasthttp.Client{DisableHeaderNamesNormalizing: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSimpleClient(tt.opt).transport.(*FastTransport).Client
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fasthttp.Client = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSimpleClient_Options(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path":"` + r.URL.RequestURI() + `","ua":"` + r.UserAgent() + `","token":"` + r.Header.Get("X-Token") + `"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+		req  *Request
+		want map[string]string
+	}{
+		{
+			name: "base url with relative path.",
+			opts: []Option{WithBaseURL(srv.URL + "/v1/")},
+			req:  NewRequest().Get("/users"),
+			want: map[string]string{"path": "/v1/users", "ua": "fasthttp", "token": ""},
+		},
+		{
+			name: "base url with path params.",
+			opts: []Option{WithBaseURL(srv.URL + "/v1")},
+			req:  NewRequest().Get("/users/{id}/orders/{orderId}").PathParam("id", 42).PathParam("orderId", "a b"),
+			want: map[string]string{"path": "/v1/users/42/orders/a%20b", "ua": "fasthttp", "token": ""},
+		},
+		{
+			name: "base url ignored for absolute url.",
+			opts: []Option{WithBaseURL("http://bad.example.com")},
+			req:  NewRequest().Get(srv.URL + "/users"),
+			want: map[string]string{"path": "/users", "ua": "fasthttp", "token": ""},
+		},
+		{
+			name: "default header and user agent.",
+			opts: []Option{WithUserAgent("sgh-test"), WithHeader("X-Token", "default")},
+			req:  NewRequest().Get(srv.URL),
+			want: map[string]string{"path": "/", "ua": "sgh-test", "token": "default"},
+		},
+		{
+			name: "query options.",
+			opts: []Option{WithQueryOptions(utils.QueryOptions{ArrayStyle: utils.ArrayBracket})},
+			req:  NewRequest().Get(srv.URL).QueryStruct(map[string][]string{"a": {"1"}}),
+			want: map[string]string{"path": "/?a%5B%5D=1", "ua": "fasthttp", "token": ""},
+		},
+		{
+			name: "request query options override client.",
+			opts: []Option{WithQueryOptions(utils.QueryOptions{ArrayStyle: utils.ArrayBracket})},
+			req:  NewRequest().Get(srv.URL).QueryStruct(map[string][]string{"a": {"1", "2"}}).SetQueryOptions(utils.QueryOptions{ArrayStyle: utils.ArrayComma}),
+			want: map[string]string{"path": "/?a=1%2C2", "ua": "fasthttp", "token": ""},
+		},
+		{
+			name: "request header override default header.",
+			opts: []Option{WithHeader("X-Token", "default")},
+			req:  NewRequest().Get(srv.URL).SetHeader("X-Token", "request"),
+			want: map[string]string{"path": "/", "ua": "fasthttp", "token": "request"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			if err := NewSimpleClient(tt.opts...).Do(tt.req, NewDefaultResponse(&got)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Response.Result = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		ref  string
+		want string
+	}{
+		{name: "no base.", base: "", ref: "/users", want: "/users"},
+		{name: "absolute ref.", base: "https://api.example.com", ref: "https://other.com/a", want: "https://other.com/a"},
+		{name: "join path.", base: "https://api.example.com/v1", ref: "/users", want: "https://api.example.com/v1/users"},
+		{name: "join trailing slash.", base: "https://api.example.com/v1/", ref: "users", want: "https://api.example.com/v1/users"},
+		{name: "empty ref.", base: "https://api.example.com/v1", ref: "", want: "https://api.example.com/v1"},
+		{name: "url in query.", base: "https://api.example.com/v1", ref: "/redirect?to=http://x.com", want: "https://api.example.com/v1/redirect?to=http://x.com"},
+		{name: "query only.", base: "https://api.example.com/v1", ref: "?key=value", want: "https://api.example.com/v1?key=value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveURL(tt.base, tt.ref); got != tt.want {
+				t.Errorf("resolveURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package sgh
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Redactor 在记录日志前遮盖敏感的请求头与请求体、响应体字段，不影响实际发送的内容。
+type Redactor struct {
+	// 需要遮盖的请求头与响应头，不区分大小写
+	Headers []string
+	// JSON 字段路径，以 . 分隔，* 匹配任意字段，数组会逐个元素匹配，如 "user.password"、"items.*.token"
+	JsonPaths []string
+	// XML 元素名，遮盖任意层级中该元素的文本内容
+	XmlElements []string
+	// 替换敏感内容的文本，为空时使用 "***"
+	Mask string
+}
+
+// DefaultRedactor 返回 SimpleClient 默认使用的 Redactor，遮盖常见的认证相关请求头。
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+	}
+}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return "***"
+	}
+	return r.Mask
+}
+
+// header 返回遮盖后的副本，r 为 nil 时原样返回。
+func (r *Redactor) header(h http.Header) http.Header {
+	if r == nil || len(h) == 0 {
+		return h
+	}
+	// 直接赋值的请求头或未规范化的响应头名称可能不是规范形式，逐个忽略大小写比较
+	var out http.Header
+	for key, values := range h {
+		if !r.sensitive(key) {
+			continue
+		}
+		if out == nil {
+			out = h.Clone()
+		}
+		masked := make([]string, len(values))
+		for i := range masked {
+			masked[i] = r.mask()
+		}
+		out[key] = masked
+	}
+	if out == nil {
+		return h
+	}
+	return out
+}
+
+// sensitive 判断 key 是否为需要遮盖的头，不区分大小写。
+func (r *Redactor) sensitive(key string) bool {
+	for _, name := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// body 按 contentType 遮盖 JSON 字段或 XML 元素，无法解析时原样返回。
+func (r *Redactor) body(contentType string, body []byte) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case len(r.JsonPaths) > 0 && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")):
+		return r.jsonBody(body)
+	case len(r.XmlElements) > 0 && (mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")):
+		return r.xmlBody(body)
+	}
+	return body
+}
+
+func (r *Redactor) jsonBody(body []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return body
+	}
+	for _, path := range r.JsonPaths {
+		path = strings.TrimPrefix(path, "$.")
+		v = maskJson(v, strings.Split(path, "."), r.mask())
+	}
+	var out bytes.Buffer
+	e := json.NewEncoder(&out)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return body
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n"))
+}
+
+// maskJson 将 v 中匹配 path 的值替换为 mask。
+func maskJson(v interface{}, path []string, mask string) interface{} {
+	if len(path) == 0 {
+		return mask
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if path[0] == "*" || path[0] == k {
+				v[k] = maskJson(child, path[1:], mask)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = maskJson(child, path, mask)
+		}
+	}
+	return v
+}
+
+func (r *Redactor) xmlBody(body []byte) []byte {
+	for _, name := range r.XmlElements {
+		// 仅匹配只包含文本的元素，允许命名空间前缀与属性
+		pattern := regexp.MustCompile(`(<(?:[\w.-]+:)?` + regexp.QuoteMeta(name) + `(?:\s[^>]*)?>)[^<]*(</(?:[\w.-]+:)?` + regexp.QuoteMeta(name) + `\s*>)`)
+		body = pattern.ReplaceAll(body, []byte("${1}"+strings.ReplaceAll(r.mask(), "$", "$$")+"${2}"))
+	}
+	return body
+}
@@ -0,0 +1,132 @@
+package sgh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRedactor_header(t *testing.T) {
+	h := http.Header{
+		"Authorization": {"Bearer token"},
+		"Set-Cookie":    {"a=1", "b=2"},
+		"Accept":        {"*/*"},
+	}
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		h        http.Header
+		want     http.Header
+	}{
+		{name: "nil.", redactor: nil, want: h},
+		{
+			name:     "non canonical keys.",
+			redactor: DefaultRedactor(),
+			h:        http.Header{"authorization": {"Bearer secret"}, "set-cookie": {"a=1"}, "accept": {"*/*"}},
+			want:     http.Header{"authorization": {"***"}, "set-cookie": {"***"}, "accept": {"*/*"}},
+		},
+		{
+			name:     "default.",
+			redactor: DefaultRedactor(),
+			want:     http.Header{"Authorization": {"***"}, "Set-Cookie": {"***", "***"}, "Accept": {"*/*"}},
+		},
+		{
+			name:     "custom header and mask.",
+			redactor: &Redactor{Headers: []string{"accept"}, Mask: "[hidden]"},
+			want:     http.Header{"Authorization": {"Bearer token"}, "Set-Cookie": {"a=1", "b=2"}, "Accept": {"[hidden]"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := h
+			if tt.h != nil {
+				in = tt.h
+			}
+			if got := tt.redactor.header(in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redactor.header() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if h.Get("Authorization") != "Bearer token" {
+		t.Errorf("Redactor.header() modified origin header")
+	}
+}
+
+func TestRedactor_body(t *testing.T) {
+	redactor := &Redactor{
+		JsonPaths:   []string{"password", "user.token", "$.items.*.secret"},
+		XmlElements: []string{"password"},
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "json.",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name":"a","password":"p","user":{"token":"t","id":1}}`,
+			want:        `{"name":"a","password":"***","user":{"id":1,"token":"***"}}`,
+		},
+		{
+			name:        "json array and wildcard.",
+			contentType: "application/vnd.api+json",
+			body:        `[{"items":{"x":{"secret":"s1"},"y":{"secret":"s2","keep":1}}}]`,
+			want:        `[{"items":{"x":{"secret":"***"},"y":{"keep":1,"secret":"***"}}}]`,
+		},
+		{name: "invalid json.", contentType: "application/json", body: `{"password":`, want: `{"password":`},
+		{
+			name:        "xml.",
+			contentType: "text/xml",
+			body:        `<login><user>a</user><password>p</password><ns:password type="x">q</ns:password></login>`,
+			want:        `<login><user>a</user><password>***</password><ns:password type="x">***</ns:password></login>`,
+		},
+		{name: "other content type.", contentType: "text/plain", body: `password=p`, want: `password=p`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.body(tt.contentType, []byte(tt.body)); string(got) != tt.want {
+				t.Errorf("Redactor.body() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_Redact(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"token":"t"}`))
+	}))
+	defer srv.Close()
+
+	logger := &recordLogger{}
+	redactor := DefaultRedactor()
+	redactor.JsonPaths = []string{"password", "token"}
+	sc := NewSimpleClient(WithLogger(logger), WithDebug(true), WithRedactor(redactor))
+	req := NewRequest().Post(srv.URL, map[string]string{"password": "p"}).SetHeader("Authorization", "Bearer secret")
+	if err := sc.Do(req, nil); err != nil {
+		t.Fatalf("SimpleClient.Do() error = %v", err)
+	}
+
+	if gotAuth != "Bearer secret" {
+		t.Errorf("server got Authorization = %v, want origin value", gotAuth)
+	}
+	reqLog, respLog := logger.records[0], logger.records[1]
+	if got := reqLog.args["header"].(http.Header).Get("Authorization"); got != "***" {
+		t.Errorf("request header Authorization = %v", got)
+	}
+	if got := reqLog.args["body"]; got != `{"password":"***"}` {
+		t.Errorf("request body = %v", got)
+	}
+	if got := respLog.args["header"].(http.Header).Get("Set-Cookie"); got != "***" {
+		t.Errorf("response header Set-Cookie = %v", got)
+	}
+	if got := respLog.args["body"]; got != `{"token":"***"}` {
+		t.Errorf("response body = %v", got)
+	}
+}
@@ -2,7 +2,12 @@ package sgh
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	neturl "net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -15,10 +20,43 @@ type Request struct {
 	Header      http.Header
 	Body        interface{}
 	RequestType BodyType
-	Timeout     time.Duration
-	Ctx         context.Context
+	// 不为 nil 时覆盖 RequestType 对应的 Codec
+	Codec   Codec
+	Timeout time.Duration
+	Ctx     context.Context
+	// 覆盖 SimpleClient 的 StatusPolicy
+	StatusPolicy StatusPolicy
+	// 覆盖 SimpleClient 的 RetryPolicy
+	Retry *RetryPolicy
+	// 覆盖 SimpleClient 是否记录日志的设置，为 nil 时使用客户端的配置
+	Debug *bool
+	// 替换 URL 路径中的 {name} 占位符
+	PathParams map[string]string
+	// 合并到 URL 中的查询参数
+	Queries    neturl.Values
+	QueryMerge QueryMergeMode
+	// 覆盖 SimpleClient 的查询参数编码选项，作用于 QueryStruct、UrlQuery、Form 与 multipart 字段
+	QueryOptions *utils.QueryOptions
+	// multipart/form-data 请求体的各个部分，按添加顺序发送
+	Parts []*MultipartPart
+	// Body 为 io.Reader 时的长度，0 表示自动探测，无法探测时使用 chunked 编码
+	BodySize int64
+	// Body 为 io.Reader 时用于重试的新请求体，
+	// 为 nil 且 Body 实现了 io.Seeker 时通过 Seek 回到起始位置，否则不会重试
+	GetBody func() (io.Reader, error)
+
+	client       SimpleHttp
+	queryStructs []interface{}
 }
 
+// QueryMergeMode 决定 Request 的查询参数与 URL 中已有的同名参数如何合并。
+type QueryMergeMode uint8
+
+const (
+	QueryReplace QueryMergeMode = iota // 覆盖 URL 中的同名参数
+	QueryAppend                        // 追加在 URL 中的同名参数之后
+)
+
 func NewRequest(opts ...func(*Request)) *Request {
 	req := &Request{}
 	for _, f := range opts {
@@ -50,6 +88,36 @@ func (req *Request) HttpBody(body interface{}) *Request {
 	return req
 }
 
+// AddHeader 追加请求头，保留同名请求头已有的值。
+func (req *Request) AddHeader(key, value string) *Request {
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Add(key, value)
+	return req
+}
+
+// Headers 设置多个请求头，覆盖同名请求头。
+func (req *Request) Headers(headers map[string]string) *Request {
+	for k, v := range headers {
+		req.SetHeader(k, v)
+	}
+	return req
+}
+
+// DelHeader 删除请求头，包括直接赋值的非规范化名称。
+func (req *Request) DelHeader(key string) *Request {
+	req.Header.Del(key)
+	delete(req.Header, key)
+	return req
+}
+
+// RawBody 原样发送已编码的 body，并设置 Content-Type。
+func (req *Request) RawBody(contentType string, body []byte) *Request {
+	req.Body = body
+	return req.SetHeader("Content-Type", contentType)
+}
+
 func (req *Request) SetHeader(key, value string) *Request {
 	if req.Header == nil {
 		req.Header = http.Header{}
@@ -68,86 +136,394 @@ func (req *Request) SetRequestType(bt BodyType) *Request {
 	return req
 }
 
+// SetCodec 指定编码请求体使用的 Codec。
+func (req *Request) SetCodec(codec Codec) *Request {
+	req.Codec = codec
+	return req
+}
+
 func (req *Request) SetTimeout(t time.Duration) *Request {
 	req.Timeout = t
 	return req
 }
 
-func (req *Request) build() (method HttpMethod, url string, header http.Header, body []byte) {
-	defer func() { reqFormatPrint(method, url, header, body) }()
-	method = req.Method
-	url = req.URL
+func (req *Request) SetStatusPolicy(policy StatusPolicy) *Request {
+	req.StatusPolicy = policy
+	return req
+}
+
+func (req *Request) SetRetryPolicy(policy *RetryPolicy) *Request {
+	req.Retry = policy
+	return req
+}
+
+// SetDebug 开启或关闭该请求的日志，覆盖 WithDebug 的设置，客户端未设置 Logger 时写入 stderr。
+func (req *Request) SetDebug(debug bool) *Request {
+	req.Debug = &debug
+	return req
+}
+
+// PathParam 设置路径参数，value 经过转义后替换 URL 中的 {key}。
+func (req *Request) PathParam(key string, value interface{}) *Request {
+	if req.PathParams == nil {
+		req.PathParams = map[string]string{}
+	}
+	req.PathParams[key] = fmt.Sprint(value)
+	return req
+}
+
+// Query 追加一个查询参数。
+func (req *Request) Query(key, value string) *Request {
+	if req.Queries == nil {
+		req.Queries = neturl.Values{}
+	}
+	req.Queries.Add(key, value)
+	return req
+}
+
+// QueryParams 追加多个查询参数。
+func (req *Request) QueryParams(values neturl.Values) *Request {
+	if req.Queries == nil {
+		req.Queries = neturl.Values{}
+	}
+	for k, v := range values {
+		req.Queries[k] = append(req.Queries[k], v...)
+	}
+	return req
+}
+
+// QueryStruct 追加由结构体或 map 编码的查询参数，编码规则见 utils.Struct2UrlValues。
+func (req *Request) QueryStruct(v interface{}) *Request {
+	req.queryStructs = append(req.queryStructs, v)
+	return req
+}
+
+// SetBodyStream 使用 r 作为请求体流式发送，size 为已知长度，未知时传 0。
+func (req *Request) SetBodyStream(r io.Reader, size int64) *Request {
+	req.Body = r
+	req.BodySize = size
+	return req
+}
+
+// SetQueryOptions 设置查询参数与表单的编码选项，如数组格式、嵌套格式与时间格式。
+func (req *Request) SetQueryOptions(opts utils.QueryOptions) *Request {
+	req.QueryOptions = &opts
+	return req
+}
+
+func (req *Request) SetQueryMerge(mode QueryMergeMode) *Request {
+	req.QueryMerge = mode
+	return req
+}
+
+// Build 构建实际发送的 Call 以便检查，使用 Client 指定的客户端或默认客户端的配置。
+// 客户端没有 Build 方法时仅使用全局注册的 Codec，且不填充 Ctx 与 Timeout。
+func (req *Request) Build() (*Call, error) {
+	client := req.client
+	if client == nil {
+		client = defaultClient
+	}
+	if b, ok := client.(interface{ Build(*Request) (*Call, error) }); ok {
+		return b.Build(req)
+	}
+	return req.build(defaultCodecs, utils.QueryOptions{})
+}
+
+// Validate 检查请求能否构建，例如路径参数是否齐全、请求体能否编码。
+func (req *Request) Validate() error {
+	_, err := req.Build()
+	return err
+}
+
+// build 构建实际发送的 Call，Ctx 与 Timeout 由 SimpleClient 填充。
+// opts 为客户端的查询参数编码选项，Request.QueryOptions 不为 nil 时优先使用。
+func (req *Request) build(codecs *codecRegistry, opts utils.QueryOptions) (call *Call, err error) {
+	if req.QueryOptions != nil {
+		opts = *req.QueryOptions
+	}
+	call = &Call{Request: req, Method: req.Method}
+	if call.URL, err = fillPathParams(req.URL, req.PathParams); err != nil {
+		return
+	}
 	if req.Header == nil {
-		header = http.Header{}
+		call.Header = http.Header{}
 	} else {
-		header = req.Header.Clone()
+		call.Header = req.Header.Clone()
 	}
 
-	// safe check
-	if req.Body == nil {
-		return
+	// 查询参数独立于请求体，POST 等方法同样可以携带
+	queries := neturl.Values{}
+	for k, v := range req.Queries {
+		queries[k] = append(queries[k], v...)
+	}
+	for _, obj := range req.queryStructs {
+		values, qerr := utils.Struct2UrlValues(obj, opts)
+		if qerr != nil {
+			err = newEncodeError(UrlQuery, obj, qerr)
+			return
+		}
+		for k, v := range values {
+			queries[k] = append(queries[k], v...)
+		}
 	}
 
 	// use default option.
+	// has multipart parts will use multipart body
 	// method == get will use url query body
 	// else use json body.
 	rt := req.RequestType
 	if rt == Default {
-		if req.Method == GET {
+		switch {
+		case len(req.Parts) > 0:
+			rt = Multipart
+		case req.Method == GET:
 			rt = UrlQuery
-		} else {
+		default:
 			rt = Json
 		}
 	}
 
-	switch rt {
-	case Json:
-		body = utils.Struct2Json(req.Body)
-		header.Set("Content-Type", "application/json")
-	case Xml:
-		body = utils.Struct2Xml(req.Body)
-		header.Set("Content-Type", "application/xml")
-	case UrlQuery:
-		query := utils.Struct2UrlQuery(req.Body)
-		if req.Method == GET {
-			if strings.Contains(req.URL, "?") {
-				url += "&" + string(query)
-			} else {
-				url += "?" + string(query)
+	codec := req.Codec
+	if codec == nil && rt != Multipart {
+		codec = codecs.byType(rt)
+	}
+
+	var bodyQuery neturl.Values
+	if r, ok := req.Body.(io.Reader); ok && rt != Multipart {
+		// 流式请求体原样发送，未显式指定格式时按二进制流处理
+		if err = req.buildStream(call, r); err != nil {
+			return
+		}
+		req.setRawContentType(call, codec, "application/octet-stream")
+	} else if raw, ok := req.Body.(json.RawMessage); ok && rt != Multipart {
+		call.Body = raw
+		req.setRawContentType(call, codec, "application/json")
+	} else if raw, ok := req.Body.([]byte); ok && rt != Multipart {
+		// 预先编码的请求体原样发送
+		call.Body = raw
+		req.setRawContentType(call, codec, "application/octet-stream")
+	} else if rt == Multipart {
+		var fields neturl.Values
+		if req.Body != nil {
+			if fields, err = utils.Struct2UrlValues(req.Body, opts); err != nil {
+				err = newEncodeError(Multipart, req.Body, err)
+				return
+			}
+		}
+		stream, contentType := newMultipartBody(fields, req.Parts)
+		call.BodyStream, call.BodySize = stream, -1
+		call.Header.Set("Content-Type", contentType)
+	} else if req.Body != nil {
+		if codec == nil {
+			err = fmt.Errorf("sgh: no codec registered for body type %v", rt)
+			return
+		}
+		var body []byte
+		if qc, ok := codec.(queryCodec); ok {
+			body, err = qc.marshal(req.Body, opts)
+		} else {
+			body, err = codec.Marshal(req.Body)
+		}
+		if err != nil {
+			err = newEncodeError(rt, req.Body, err)
+			return
+		}
+		if req.Codec == nil && rt == UrlQuery && req.Method == GET {
+			if bodyQuery, err = neturl.ParseQuery(string(body)); err != nil {
+				err = newEncodeError(rt, req.Body, err)
+				return
 			}
 		} else {
-			body = query
+			call.Body = body
+			ct := codec.ContentType()
+			if ct == "" && rt == UrlQuery {
+				// 非 GET 请求的 UrlQuery 作为请求体发送，与 Form 相同
+				ct = formCodec.ContentType()
+			}
+			if ct != "" {
+				call.Header.Set("Content-Type", ct)
+			}
 		}
 	}
+
+	call.URL = mergeQuery(call.URL, req.QueryMerge, bodyQuery, queries)
 	return
 }
 
-func (req *Request) Do(resp *Response, opts ...func(*Request, *Response)) error {
-	return defaultClient.Do(req, resp, opts...)
+// setRawContentType 为原样发送的请求体设置 Content-Type：
+// 已通过 RawBody 或 SetHeader 设置时保持不变；否则显式指定了 RequestType 或 Codec 时使用 Codec 的 Content-Type，
+// 未指定时使用 fallback。
+func (req *Request) setRawContentType(call *Call, codec Codec, fallback string) {
+	if call.Header.Get("Content-Type") != "" {
+		return
+	}
+	ct := fallback
+	if req.Codec != nil || req.RequestType != Default {
+		ct = ""
+		if codec != nil {
+			ct = codec.ContentType()
+		}
+	}
+	if ct != "" {
+		call.Header.Set("Content-Type", ct)
+	}
 }
 
-func reqFormatPrint(method HttpMethod, url string, header http.Header, body []byte) {
-	if !debug {
-		return
+func (req *Request) buildStream(call *Call, r io.Reader) error {
+	size := req.BodySize
+	if size <= 0 {
+		size = -1
+		if l, ok := r.(interface{ Len() int }); ok {
+			size = int64(l.Len())
+		}
+	}
+
+	call.GetBody = req.GetBody
+	if seeker, ok := r.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if size < 0 {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+			size = end - start
+		}
+		if call.GetBody == nil {
+			call.GetBody = func() (io.Reader, error) {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return readerOnly{r}, nil
+			}
+		}
+	}
+	// 隐藏 Close 等方法，避免底层 Transport 发送完毕后关闭调用方的 reader
+	call.BodyStream, call.BodySize = readerOnly{r}, size
+	return nil
+}
+
+type readerOnly struct {
+	io.Reader
+}
+
+// Client 指定 Do 使用的客户端，未指定时使用 SetDefaultClient 设置的默认客户端。
+func (req *Request) Client(client SimpleHttp) *Request {
+	req.client = client
+	return req
+}
+
+func (req *Request) Do(resp *Response, hooks ...Hook) error {
+	if req.client != nil {
+		return req.client.Do(req, resp, hooks...)
 	}
+	return defaultClient.Do(req, resp, hooks...)
+}
 
-	sb := strings.Builder{}
+// mergeQuery 将 params 依次按 mode 合并到 rawURL 的查询参数中。
+// 仅改写被合并的键，其余参数（包括 ; 分隔、无值的参数）与 fragment 原样保留，新增的键按键名排序追加在末尾。
+func mergeQuery(rawURL string, mode QueryMergeMode, params ...neturl.Values) string {
+	merged := neturl.Values{}
+	for _, p := range params {
+		for k, v := range p {
+			if mode == QueryAppend {
+				merged[k] = append(merged[k], v...)
+			} else {
+				merged[k] = append([]string(nil), v...)
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return rawURL
+	}
 
-	sb.WriteString("==== http request info ====\n")
-	sb.WriteString(method.String())
-	sb.WriteString(" ")
-	sb.WriteString(url)
-	sb.WriteString("\n")
-	for k := range header {
-		sb.WriteString(k)
-		sb.WriteString(": ")
-		sb.WriteString(header.Get(k))
-		sb.WriteString("\n")
+	base, fragment := rawURL, ""
+	if i := strings.Index(base, "#"); i >= 0 {
+		base, fragment = base[:i], base[i:]
+	}
+	rawQuery := ""
+	if i := strings.Index(base, "?"); i >= 0 {
+		base, rawQuery = base[:i], base[i+1:]
+	}
+
+	var pairs []string
+	if rawQuery != "" {
+		pairs = strings.Split(rawQuery, "&")
+	}
+	last := map[string]int{}
+	for i, pair := range pairs {
+		last[queryKey(pair)] = i
 	}
-	if len(body) > 0 {
-		sb.WriteString("\n")
-		sb.Write(body)
-		sb.WriteString("\n")
+	var out []string
+	replaced := map[string]bool{}
+	add := func(k string) {
+		if s := (neturl.Values{k: merged[k]}).Encode(); s != "" {
+			out = append(out, s)
+		}
+		delete(merged, k)
+	}
+	for i, pair := range pairs {
+		k := queryKey(pair)
+		switch _, ok := merged[k]; {
+		case pair == "" || replaced[k]:
+			// 丢弃空参数与已被替换的同名参数
+		case !ok:
+			out = append(out, pair)
+		case mode == QueryAppend:
+			// 追加在同名参数的最后一次出现之后
+			out = append(out, pair)
+			if i == last[k] {
+				add(k)
+			}
+		default:
+			// 在首次出现处替换
+			replaced[k] = true
+			add(k)
+		}
+	}
+	if rest := merged.Encode(); rest != "" {
+		out = append(out, rest)
+	}
+	return base + "?" + strings.Join(out, "&") + fragment
+}
+
+// queryKey 返回原始查询参数 pair 解码后的键名，无法解码时返回原始键名。
+func queryKey(pair string) string {
+	if i := strings.Index(pair, "="); i >= 0 {
+		pair = pair[:i]
+	}
+	if k, err := neturl.QueryUnescape(pair); err == nil {
+		return k
+	}
+	return pair
+}
+
+var pathParamPattern = regexp.MustCompile(`\{[^{}/?#]+\}`)
+
+// fillPathParams 替换 rawURL 路径部分的占位符，存在未填充的占位符时返回 ErrMissingPathParam。
+func fillPathParams(rawURL string, params map[string]string) (string, error) {
+	path, rest := rawURL, ""
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		path, rest = rawURL[:i], rawURL[i:]
+	}
+
+	var missing []string
+	path = pathParamPattern.ReplaceAllStringFunc(path, func(holder string) string {
+		name := holder[1 : len(holder)-1]
+		value, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return holder
+		}
+		return neturl.PathEscape(value)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s in %s", ErrMissingPathParam, strings.Join(missing, ", "), rawURL)
 	}
-	print(sb.String())
+	return path + rest, nil
 }
@@ -2,9 +2,15 @@ package sgh
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/SmallTianTian/simple-go-http/utils"
 )
 
 func TestNewRequest(t *testing.T) {
@@ -540,7 +546,11 @@ func TestRequest_build(t *testing.T) {
 		Header      http.Header
 		Body        interface{}
 		RequestType BodyType
+		Codec       Codec
 		Ctx         context.Context
+		PathParams  map[string]string
+		Queries     url.Values
+		QueryMerge  QueryMergeMode
 	}
 	tests := []struct {
 		name       string
@@ -549,6 +559,7 @@ func TestRequest_build(t *testing.T) {
 		wantUrl    string
 		wantHeader http.Header
 		wantBody   []byte
+		wantErr    error
 	}{
 		{
 			name:       "default request in get will be url query.",
@@ -594,7 +605,7 @@ func TestRequest_build(t *testing.T) {
 			name:       "post use url query body.",
 			fields:     fields{RequestType: UrlQuery, Method: POST, Body: map[string]string{"key": "value"}},
 			wantMethod: POST,
-			wantHeader: http.Header{},
+			wantHeader: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded; charset=utf-8"}},
 			wantBody:   []byte(`key=value`),
 		},
 		{
@@ -602,7 +613,7 @@ func TestRequest_build(t *testing.T) {
 			fields:     fields{RequestType: UrlQuery, Method: POST, Body: map[string]string{"key": "value"}, URL: "http://example.com?test=hello"},
 			wantMethod: POST,
 			wantUrl:    "http://example.com?test=hello",
-			wantHeader: http.Header{},
+			wantHeader: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded; charset=utf-8"}},
 			wantBody:   []byte(`key=value`),
 		},
 		{
@@ -625,7 +636,7 @@ func TestRequest_build(t *testing.T) {
 			name:       "url query accept struct",
 			fields:     fields{RequestType: UrlQuery, Method: GET, Body: map[string]interface{}{"struct": map[string]string{"sk": "sv"}}, URL: "http://example.com?test=hello"},
 			wantMethod: GET,
-			wantUrl:    `http://example.com?test=hello&struct={"sk":"sv"}`,
+			wantUrl:    `http://example.com?test=hello&struct%5Bsk%5D=sv`,
 			wantHeader: http.Header{},
 			wantBody:   nil,
 		},
@@ -648,7 +659,7 @@ func TestRequest_build(t *testing.T) {
 				RequestType: UrlQuery,
 			},
 			wantMethod: POST,
-			wantHeader: http.Header{},
+			wantHeader: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded; charset=utf-8"}},
 			wantBody:   []byte(`key=value`),
 		},
 		{
@@ -665,8 +676,44 @@ func TestRequest_build(t *testing.T) {
 			wantMethod: POST,
 			wantUrl:    "",
 			wantHeader: http.Header{"Content-Type": []string{"application/json"}},
+			wantBody:   []byte(`"this is string."`),
+		},
+		{
+			name:       "use string to post with legacy codec",
+			fields:     fields{Method: POST, Body: "this is string.", Codec: LegacyJsonCodec{}},
+			wantMethod: POST,
+			wantUrl:    "",
+			wantHeader: http.Header{"Content-Type": []string{"application/json"}},
 			wantBody:   []byte(`this is string.`),
 		},
+		{
+			name:       "use bytes to post",
+			fields:     fields{Method: POST, Body: []byte(`{"raw":true}`)},
+			wantMethod: POST,
+			wantHeader: http.Header{"Content-Type": []string{"application/octet-stream"}},
+			wantBody:   []byte(`{"raw":true}`),
+		},
+		{
+			name:       "use bytes to json post",
+			fields:     fields{Method: POST, Body: []byte(`{"raw":true}`), RequestType: Json},
+			wantMethod: POST,
+			wantHeader: http.Header{"Content-Type": []string{"application/json"}},
+			wantBody:   []byte(`{"raw":true}`),
+		},
+		{
+			name:       "use bytes with content type header",
+			fields:     fields{Method: PUT, Body: []byte(`a: 1`), Header: http.Header{"Content-Type": []string{"application/yaml"}}},
+			wantMethod: PUT,
+			wantHeader: http.Header{"Content-Type": []string{"application/yaml"}},
+			wantBody:   []byte(`a: 1`),
+		},
+		{
+			name:       "use json raw message to post",
+			fields:     fields{Method: POST, Body: json.RawMessage(`{"raw":true}`)},
+			wantMethod: POST,
+			wantHeader: http.Header{"Content-Type": []string{"application/json"}},
+			wantBody:   []byte(`{"raw":true}`),
+		},
 		{
 			name:       "use string to xml post",
 			fields:     fields{Method: POST, Body: "this is string.", RequestType: Xml},
@@ -675,6 +722,107 @@ func TestRequest_build(t *testing.T) {
 			wantHeader: http.Header{"Content-Type": []string{"application/xml"}},
 			wantBody:   []byte(`this is string.`),
 		},
+		{
+			name: "fill path params.",
+			fields: fields{
+				Method:     GET,
+				URL:        "https://example.com/users/{id}/orders/{orderId}?test={raw}",
+				PathParams: map[string]string{"id": "42", "orderId": "a b/c"},
+			},
+			wantMethod: GET,
+			wantUrl:    "https://example.com/users/42/orders/a%20b%2Fc?test={raw}",
+			wantHeader: http.Header{},
+		},
+		{
+			name: "unfilled path params.",
+			fields: fields{
+				Method:     GET,
+				URL:        "https://example.com/users/{id}/orders/{orderId}",
+				PathParams: map[string]string{"id": "42"},
+			},
+			wantErr: ErrMissingPathParam,
+		},
+		{
+			name:       "form body.",
+			fields:     fields{RequestType: Form, Method: POST, Body: map[string]interface{}{"b": "x y", "a": []int{1, 2}}},
+			wantMethod: POST,
+			wantHeader: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded; charset=utf-8"}},
+			wantBody:   []byte(`a=1&a=2&b=x+y`),
+		},
+		{
+			name:       "form body from url values.",
+			fields:     fields{RequestType: Form, Method: PUT, Body: url.Values{"k": []string{"&"}}},
+			wantMethod: PUT,
+			wantHeader: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded; charset=utf-8"}},
+			wantBody:   []byte(`k=%26`),
+		},
+		{
+			name: "post carry query params.",
+			fields: fields{
+				Method:  POST,
+				URL:     "https://example.com/path?a=1#frag",
+				Body:    map[string]string{"key": "value"},
+				Queries: url.Values{"b": []string{"x y", "&"}},
+			},
+			wantMethod: POST,
+			wantUrl:    "https://example.com/path?a=1&b=x+y&b=%26#frag",
+			wantHeader: http.Header{"Content-Type": []string{"application/json"}},
+			wantBody:   []byte(`{"key":"value"}`),
+		},
+		{
+			name: "query replace existing key.",
+			fields: fields{
+				Method:  GET,
+				URL:     "https://example.com?a=1&a=2&b=3",
+				Body:    map[string]string{"b": "body"},
+				Queries: url.Values{"a": []string{"query"}},
+			},
+			wantMethod: GET,
+			wantUrl:    "https://example.com?a=query&b=body",
+			wantHeader: http.Header{},
+		},
+		{
+			name: "query append existing key.",
+			fields: fields{
+				Method:     GET,
+				URL:        "https://example.com?a=1&b=3",
+				Body:       map[string]string{"b": "body"},
+				Queries:    url.Values{"a": []string{"query"}},
+				QueryMerge: QueryAppend,
+			},
+			wantMethod: GET,
+			wantUrl:    "https://example.com?a=1&a=query&b=3&b=body",
+			wantHeader: http.Header{},
+		},
+		{
+			name: "semicolon query kept.",
+			fields: fields{
+				Method:  GET,
+				URL:     "https://example.com?a=1;b=2",
+				Queries: url.Values{"c": []string{"3"}},
+			},
+			wantMethod: GET,
+			wantUrl:    "https://example.com?a=1;b=2&c=3",
+			wantHeader: http.Header{},
+		},
+		{
+			name: "untouched pairs kept raw.",
+			fields: fields{
+				Method:  GET,
+				URL:     "https://example.com?flag&b=%20&c=1",
+				Queries: url.Values{"c": []string{"3"}},
+			},
+			wantMethod: GET,
+			wantUrl:    "https://example.com?flag&b=%20&c=3",
+			wantHeader: http.Header{},
+		},
+		{
+			name:       "no query params keep url untouched.",
+			fields:     fields{Method: GET, URL: "https://example.com?b=1&a=2"},
+			wantMethod: GET,
+			wantUrl:    "https://example.com?b=1&a=2",
+			wantHeader: http.Header{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -684,9 +832,20 @@ func TestRequest_build(t *testing.T) {
 				Header:      tt.fields.Header,
 				Body:        tt.fields.Body,
 				RequestType: tt.fields.RequestType,
+				Codec:       tt.fields.Codec,
 				Ctx:         tt.fields.Ctx,
+				PathParams:  tt.fields.PathParams,
+				Queries:     tt.fields.Queries,
+				QueryMerge:  tt.fields.QueryMerge,
 			}
-			gotMethod, gotUrl, gotHeader, gotBody := req.build()
+			call, err := req.build(defaultCodecs, utils.QueryOptions{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Request.build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			gotMethod, gotUrl, gotHeader, gotBody := call.Method, call.URL, call.Header, call.Body
 			if gotMethod != tt.wantMethod {
 				t.Errorf("Request.build() gotMethod = %v, want %v", gotMethod, tt.wantMethod)
 			}
@@ -744,3 +903,244 @@ func TestRequest_SetRequestType(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_PathParam(t *testing.T) {
+	type args struct {
+		key   string
+		value interface{}
+	}
+	tests := []struct {
+		name   string
+		params map[string]string
+		args   args
+		want   *Request
+	}{
+		{
+			name: "set string path param.",
+			args: args{key: "id", value: "abc"},
+			want: &Request{PathParams: map[string]string{"id": "abc"}},
+		},
+		{
+			name: "set number path param.",
+			args: args{key: "id", value: 42},
+			want: &Request{PathParams: map[string]string{"id": "42"}},
+		},
+		{
+			name:   "set path param will reset the same key.",
+			params: map[string]string{"id": "old", "name": "tian"},
+			args:   args{key: "id", value: 42},
+			want:   &Request{PathParams: map[string]string{"id": "42", "name": "tian"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{PathParams: tt.params}
+			if got := req.PathParam(tt.args.key, tt.args.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Request.PathParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Query(t *testing.T) {
+	type filter struct {
+		Status string `url:"status"`
+	}
+	tests := []struct {
+		name string
+		req  *Request
+		want string
+	}{
+		{
+			name: "query.",
+			req:  NewRequest().Get("https://example.com").Query("a", "1").Query("a", "2"),
+			want: "https://example.com?a=1&a=2",
+		},
+		{
+			name: "query params.",
+			req:  NewRequest().Get("https://example.com").QueryParams(url.Values{"a": []string{"1"}, "b": []string{"2"}}),
+			want: "https://example.com?a=1&b=2",
+		},
+		{
+			name: "query struct.",
+			req:  NewRequest().Get("https://example.com").QueryStruct(filter{Status: "open"}),
+			want: "https://example.com?status=open",
+		},
+		{
+			name: "all together.",
+			req: NewRequest().Get("https://example.com?z=0").
+				Query("a", "1").
+				QueryParams(url.Values{"b": []string{"2"}}).
+				QueryStruct(filter{Status: "open"}),
+			want: "https://example.com?z=0&a=1&b=2&status=open",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, err := tt.req.build(defaultCodecs, utils.QueryOptions{})
+			if err != nil {
+				t.Fatalf("Request.build() error = %v", err)
+			}
+			if got := call.URL; got != tt.want {
+				t.Errorf("Request.build() gotUrl = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_SetQueryOptions(t *testing.T) {
+	type filter struct {
+		Ids     []int `url:"ids"`
+		Address struct {
+			City string `url:"city"`
+		} `url:"address"`
+	}
+	f := filter{Ids: []int{1, 2}}
+	f.Address.City = "sh"
+	opts := utils.QueryOptions{ArrayStyle: utils.ArrayComma, NestStyle: utils.NestDot}
+
+	tests := []struct {
+		name     string
+		req      *Request
+		wantUrl  string
+		wantBody string
+	}{
+		{
+			name:    "query struct.",
+			req:     NewRequest().Get("https://example.com").QueryStruct(f).SetQueryOptions(opts),
+			wantUrl: "https://example.com?address.city=sh&ids=1%2C2",
+		},
+		{
+			name:    "url query body.",
+			req:     NewRequest().Get("https://example.com").HttpBody(f).SetQueryOptions(opts),
+			wantUrl: "https://example.com?address.city=sh&ids=1%2C2",
+		},
+		{
+			name:     "form body.",
+			req:      NewRequest().Post("https://example.com", f).SetRequestType(Form).SetQueryOptions(opts),
+			wantUrl:  "https://example.com",
+			wantBody: "address.city=sh&ids=1%2C2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, err := tt.req.build(defaultCodecs, utils.QueryOptions{})
+			if err != nil {
+				t.Fatalf("Request.build() error = %v", err)
+			}
+			if call.URL != tt.wantUrl {
+				t.Errorf("Request.build() gotUrl = %v, want %v", call.URL, tt.wantUrl)
+			}
+			if string(call.Body) != tt.wantBody {
+				t.Errorf("Request.build() gotBody = %s, want %s", call.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRequest_Build(t *testing.T) {
+	sc := NewSimpleClient(
+		WithBaseURL("https://example.com/api"),
+		WithHeader("X-Default", "1"),
+		WithTimeout(time.Second),
+	)
+	call, err := NewRequest().Client(sc).Post("/users/{id}", map[string]int{"a": 1}).PathParam("id", 7).Build()
+	if err != nil {
+		t.Fatalf("Request.Build() error = %v", err)
+	}
+	if call.URL != "https://example.com/api/users/7" {
+		t.Errorf("Request.Build() gotUrl = %v", call.URL)
+	}
+	if call.Header.Get("X-Default") != "1" || call.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Request.Build() gotHeader = %v", call.Header)
+	}
+	if string(call.Body) != `{"a":1}` || call.Timeout != time.Second || call.Ctx == nil {
+		t.Errorf("Request.Build() gotBody = %s, gotTimeout = %v", call.Body, call.Timeout)
+	}
+}
+
+func TestRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *Request
+		wantErr    error
+		wantEncode *EncodeError
+	}{
+		{name: "ok.", req: NewRequest().Post("https://example.com", map[string]int{"a": 1})},
+		{name: "missing path param.", req: NewRequest().Get("https://example.com/{id}"), wantErr: ErrMissingPathParam},
+		{
+			name:       "json channel.",
+			req:        NewRequest().Post("https://example.com", make(chan int)),
+			wantEncode: &EncodeError{BodyType: Json, Type: reflect.TypeOf(make(chan int))},
+		},
+		{
+			name:       "xml func.",
+			req:        NewRequest().Post("https://example.com", map[string]func(){}).SetRequestType(Xml),
+			wantEncode: &EncodeError{BodyType: Xml, Type: reflect.TypeOf(map[string]func(){})},
+		},
+		{
+			name:       "query struct.",
+			req:        NewRequest().Get("https://example.com").QueryStruct(1),
+			wantEncode: &EncodeError{BodyType: UrlQuery, Type: reflect.TypeOf(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantEncode != nil {
+				var encodeErr *EncodeError
+				if !errors.As(err, &encodeErr) || encodeErr.BodyType != tt.wantEncode.BodyType || encodeErr.Type != tt.wantEncode.Type {
+					t.Errorf("Request.Validate() error = %v, want %v", err, tt.wantEncode)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Request.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequest_NoCodec(t *testing.T) {
+	_, err := NewRequest().Post("https://example.com", 1).SetRequestType(BodyType(99)).build(defaultCodecs, utils.QueryOptions{})
+	if want := "sgh: no codec registered for body type BodyType(99)"; err == nil || err.Error() != want {
+		t.Errorf("Request.build() error = %v, want %v", err, want)
+	}
+	err = decodeBody(&Response{Header: http.Header{}, ResultType: BodyType(99)}, new(string), defaultCodecs)
+	if want := "sgh: no codec registered for result type BodyType(99)"; err == nil || err.Error() != want {
+		t.Errorf("decodeBody() error = %v, want %v", err, want)
+	}
+}
+
+func TestRequest_RawBody(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *Request
+		want string
+	}{
+		{
+			name: "raw body content type.",
+			req:  NewRequest().HttpMethod(PUT).Url("https://example.com").RawBody("text/csv", []byte("a,b")),
+			want: "text/csv",
+		},
+		{
+			name: "raw body content type win request type.",
+			req:  NewRequest().HttpMethod(PUT).Url("https://example.com").SetRequestType(Json).RawBody("application/vnd.api+json", []byte("a,b")),
+			want: "application/vnd.api+json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call, err := tt.req.build(defaultCodecs, utils.QueryOptions{})
+			if err != nil {
+				t.Fatalf("Request.build() error = %v", err)
+			}
+			if got := call.Header.Get("Content-Type"); got != tt.want {
+				t.Errorf("Request.build() gotHeader = %v, want %v", got, tt.want)
+			}
+			if string(call.Body) != "a,b" {
+				t.Errorf("Request.build() gotBody = %s, want %s", call.Body, "a,b")
+			}
+		})
+	}
+}
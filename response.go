@@ -1,11 +1,38 @@
 package sgh
 
-import "net/http"
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProgressFunc 报告已写入的字节数，total 为 -1 表示总长度未知。
+type ProgressFunc func(written, total int64)
 
 type Response struct {
 	Header     http.Header
 	Result     interface{}
 	ResultType BodyType
+	// 状态码未通过 StatusPolicy 时，响应体解析到 ErrorResult 而非 Result
+	ErrorResult interface{}
+	// 不为 nil 时覆盖 ResultType 与 Content-Type 选择的 Codec
+	Codec Codec
+	// 为 true 时不读取完整响应体，由调用方读取并关闭 Body，Result 不会被解析
+	Stream   bool
+	Progress ProgressFunc
+
+	// 以下字段在请求完成后填充，与 Result 是否为 nil 无关
+	StatusCode    int    // e.g. 200
+	Status        string // e.g. "200 OK"
+	Proto         string // e.g. "HTTP/1.1"
+	RawBody       []byte
+	Body          io.ReadCloser // 仅在 Stream 为 true 时填充
+	ContentLength int64         // -1 表示长度未知
+	StartTime     time.Time
+	Duration      time.Duration
 }
 
 func NewResponse(resultStruct interface{}, resultType BodyType) *Response {
@@ -15,6 +42,11 @@ func NewResponse(resultStruct interface{}, resultType BodyType) *Response {
 	}
 }
 
+// NewStreamResponse 返回流式响应，请求成功后通过 Body 或 WriteTo 读取响应体。
+func NewStreamResponse() *Response {
+	return &Response{Stream: true}
+}
+
 func NewDefaultResponse(resultStruct interface{}) *Response {
 	return NewResponse(resultStruct, Default)
 }
@@ -26,3 +58,85 @@ func NewJsonResponse(resultStruct interface{}) *Response {
 func NewXmlResponse(resultStruct interface{}) *Response {
 	return NewResponse(resultStruct, Xml)
 }
+
+func NewFormResponse(resultStruct interface{}) *Response {
+	return NewResponse(resultStruct, Form)
+}
+
+// NewProtobufResponse 将 application/x-protobuf 响应体解析到 msg。
+func NewProtobufResponse(msg proto.Message) *Response {
+	return NewResponse(msg, Protobuf)
+}
+
+// NewProtoJsonResponse 使用 protojson 将响应体解析到 msg。
+func NewProtoJsonResponse(msg proto.Message) *Response {
+	return NewResponse(msg, ProtoJson)
+}
+
+func NewMsgpackResponse(resultStruct interface{}) *Response {
+	return NewResponse(resultStruct, Msgpack)
+}
+
+func NewCborResponse(resultStruct interface{}) *Response {
+	return NewResponse(resultStruct, Cbor)
+}
+
+// OnError 设置非成功状态码时响应体的解析目标。
+func (resp *Response) OnError(errorStruct interface{}) *Response {
+	resp.ErrorResult = errorStruct
+	return resp
+}
+
+// SetCodec 指定解析 Result 与 ErrorResult 使用的 Codec。
+func (resp *Response) SetCodec(codec Codec) *Response {
+	resp.Codec = codec
+	return resp
+}
+
+// SetProgress 设置 WriteTo 与 DownloadTo 的进度回调。
+func (resp *Response) SetProgress(progress ProgressFunc) *Response {
+	resp.Progress = progress
+	return resp
+}
+
+// WriteTo 将响应体写入 w 并关闭 Body，非流式响应写入 RawBody。
+func (resp *Response) WriteTo(w io.Writer) (int64, error) {
+	return resp.writeTo(w, 0, resp.ContentLength)
+}
+
+// Close 关闭流式响应体，未读取完的响应体必须关闭以释放连接。
+func (resp *Response) Close() error {
+	if resp.Body == nil {
+		return nil
+	}
+	err := resp.Body.Close()
+	resp.Body = nil
+	return err
+}
+
+// writeTo 写入响应体，进度从 offset 开始计算。
+func (resp *Response) writeTo(w io.Writer, offset, total int64) (int64, error) {
+	var body io.Reader = bytes.NewReader(resp.RawBody)
+	if resp.Body != nil {
+		defer resp.Close()
+		body = resp.Body
+	}
+	if resp.Progress != nil {
+		w = &progressWriter{w: w, written: offset, total: total, progress: resp.Progress}
+	}
+	return io.Copy(w, body)
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.written += int64(n)
+	pw.progress(pw.written, pw.total)
+	return n, err
+}
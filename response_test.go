@@ -145,3 +145,59 @@ func TestNewXMLResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFormResponse(t *testing.T) {
+	var rs map[string]string
+	tests := []struct {
+		name string
+		args interface{}
+		want *Response
+	}{
+		{
+			name: "set result.",
+			args: &rs,
+			want: &Response{Result: &rs, ResultType: Form},
+		},
+		{
+			name: "not set result.",
+			want: &Response{ResultType: Form},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewFormResponse(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewFormResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponse_OnError(t *testing.T) {
+	var rs, es map[string]string
+	tests := []struct {
+		name string
+		resp *Response
+		args interface{}
+		want *Response
+	}{
+		{
+			name: "set error result.",
+			resp: NewJsonResponse(&rs),
+			args: &es,
+			want: &Response{Result: &rs, ResultType: Json, ErrorResult: &es},
+		},
+		{
+			name: "set error result will reset error result.",
+			resp: &Response{ErrorResult: &rs},
+			args: &es,
+			want: &Response{ErrorResult: &es},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resp.OnError(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Response.OnError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
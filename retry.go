@@ -0,0 +1,223 @@
+package sgh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RetryPolicy 描述请求失败后的重试行为。
+//
+// 连接错误、超时以及 RetryStatus 中的状态码会触发重试；
+// GET/HEAD/PUT/DELETE/OPTIONS/TRACE 默认可重试，
+// POST/PATCH/CONNECT 仅在携带 IdempotencyHeader 请求头时重试。
+type RetryPolicy struct {
+	// 最大请求次数，包括首次请求，<= 1 表示不重试
+	MaxAttempts int
+	// 指数退避的初始与最大等待时间
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// 等待时间的随机缩减比例，取值 [0, 1]
+	Jitter float64
+	// 需要重试的响应状态码
+	RetryStatus []int
+	// 非幂等方法携带该请求头时允许重试
+	IdempotencyHeader string
+	// 包括所有重试与等待在内的总耗时上限，0 表示仅受 ctx 与 timeout 限制
+	MaxElapsed time.Duration
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Jitter:      0.2,
+		RetryStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		IdempotencyHeader: "Idempotency-Key",
+	}
+}
+
+// retryMiddleware 是内置的重试中间件，位于用户中间件与实际请求之间。
+// call.Request.Retry 不为 nil 时覆盖 policy。
+func retryMiddleware(policy *RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(call *Call, resp *Response) error {
+			p := policy
+			if call.Request != nil && call.Request.Retry != nil {
+				p = call.Request.Retry
+			}
+			if !p.allow(call.Method, call.Header) {
+				return next(call, resp)
+			}
+
+			ctx := call.Ctx
+			if p.MaxElapsed > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, p.MaxElapsed)
+				defer cancel()
+				attemptCall := *call
+				attemptCall.Ctx = ctx
+				call = &attemptCall
+			}
+
+			for attempt := 1; ; attempt++ {
+				err := next(call, resp)
+				wait, ok := p.backoff(attempt, resp, err)
+				if ok {
+					if deadline, has := ctx.Deadline(); has && time.Now().Add(wait).After(deadline) {
+						ok = false
+					}
+				}
+				if !ok {
+					return err
+				}
+				// 丢弃本次的流式响应体，释放连接
+				resp.Close()
+				// 流式请求体已被读取，只有提供了 GetBody 才能重试
+				if call.BodyStream != nil && call.GetBody == nil {
+					cause := resp.Status
+					if err != nil {
+						cause = err.Error()
+					}
+					return fmt.Errorf("%w: %s", ErrBodyNotRewindable, cause)
+				}
+				if err := sleepContext(ctx, wait); err != nil {
+					return &ContextError{Err: err}
+				}
+				if call.BodyStream != nil {
+					body, err := call.GetBody()
+					if err != nil {
+						return err
+					}
+					call.BodyStream = body
+				}
+			}
+		}
+	}
+}
+
+func (p *RetryPolicy) allow(method HttpMethod, header http.Header) bool {
+	if p == nil || p.MaxAttempts <= 1 {
+		return false
+	}
+	switch method {
+	case POST, PATCH, CONNECT:
+		return p.IdempotencyHeader != "" && header.Get(p.IdempotencyHeader) != ""
+	}
+	return true
+}
+
+// backoff 返回第 attempt 次请求后是否需要重试以及等待时间。
+func (p *RetryPolicy) backoff(attempt int, resp *Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if err != nil {
+		if !retryableError(err) {
+			return 0, false
+		}
+		return p.delay(attempt), true
+	}
+	if !p.retryStatus(resp.StatusCode) {
+		return 0, false
+	}
+	wait := p.delay(attempt)
+	if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok && after > wait {
+		wait = after
+	}
+	return wait, true
+}
+
+// retryableError 判断 err 是否为可重试的连接错误或超时，
+// 协议不支持、地址错误、证书错误等其余错误立即返回。
+func retryableError(err error) bool {
+	if _, ok := err.(*ContextError); ok {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	for _, target := range []error{
+		fasthttp.ErrTimeout, fasthttp.ErrDialTimeout, fasthttp.ErrConnectionClosed,
+		io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.ECONNREFUSED,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryStatus(statusCode int) bool {
+	for _, code := range p.RetryStatus {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// retryAfter 解析 Retry-After，支持秒数与 HTTP-date 两种格式。
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,274 @@
+package sgh
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRetryPolicy_allow(t *testing.T) {
+	withKey := http.Header{}
+	withKey.Set("Idempotency-Key", "abc")
+
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		method HttpMethod
+		header http.Header
+		want   bool
+	}{
+		{name: "nil policy.", policy: nil, method: GET, header: http.Header{}, want: false},
+		{name: "single attempt.", policy: &RetryPolicy{MaxAttempts: 1}, method: GET, header: http.Header{}, want: false},
+		{name: "get.", policy: DefaultRetryPolicy(), method: GET, header: http.Header{}, want: true},
+		{name: "put.", policy: DefaultRetryPolicy(), method: PUT, header: http.Header{}, want: true},
+		{name: "delete.", policy: DefaultRetryPolicy(), method: DELETE, header: http.Header{}, want: true},
+		{name: "post without idempotency key.", policy: DefaultRetryPolicy(), method: POST, header: http.Header{}, want: false},
+		{name: "post with idempotency key.", policy: DefaultRetryPolicy(), method: POST, header: withKey, want: true},
+		{name: "patch without idempotency key.", policy: DefaultRetryPolicy(), method: PATCH, header: http.Header{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.allow(tt.method, tt.header); got != tt.want {
+				t.Errorf("RetryPolicy.allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			name:    "first retry.",
+			policy:  &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+			attempt: 1,
+			min:     100 * time.Millisecond,
+			max:     100 * time.Millisecond,
+		},
+		{
+			name:    "exponential.",
+			policy:  &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+			attempt: 3,
+			min:     400 * time.Millisecond,
+			max:     400 * time.Millisecond,
+		},
+		{
+			name:    "capped by max backoff.",
+			policy:  &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+			attempt: 10,
+			min:     time.Second,
+			max:     time.Second,
+		},
+		{
+			name:    "jitter.",
+			policy:  &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5},
+			attempt: 1,
+			min:     50 * time.Millisecond,
+			max:     100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.delay(tt.attempt); got < tt.min || got > tt.max {
+				t.Errorf("RetryPolicy.delay() = %v, want [%v, %v]", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "empty.", value: "", want: 0, wantOk: false},
+		{name: "seconds.", value: "3", want: 3 * time.Second, wantOk: true},
+		{name: "negative.", value: "-1", want: 0, wantOk: false},
+		{name: "past date.", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOk: true},
+		{name: "invalid.", value: "soon", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_retryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "fasthttp timeout.", err: fasthttp.ErrTimeout, want: true},
+		{name: "fasthttp connection closed.", err: fasthttp.ErrConnectionClosed, want: true},
+		{name: "connection refused.", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, want: true},
+		{name: "connection reset.", err: &url.Error{Op: "Get", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, want: true},
+		{name: "eof.", err: &url.Error{Op: "Get", Err: io.EOF}, want: true},
+		{name: "context.", err: &ContextError{Err: context.DeadlineExceeded}, want: false},
+		{name: "unsupported scheme.", err: &url.Error{Op: "Get", Err: errors.New(`unsupported protocol scheme "ftp"`)}, want: false},
+		{name: "x509.", err: &url.Error{Op: "Get", Err: x509.UnknownAuthorityError{}}, want: false},
+		{name: "stream not supported.", err: ErrStreamNotSupported, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableError(tt.err); got != tt.want {
+				t.Errorf("retryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_Retry(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:       3,
+		MinBackoff:        time.Millisecond,
+		MaxBackoff:        10 * time.Millisecond,
+		RetryStatus:       []int{http.StatusServiceUnavailable},
+		IdempotencyHeader: "Idempotency-Key",
+	}
+
+	tests := []struct {
+		name         string
+		req          func(url string) *Request
+		failTimes    int32
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "get retry until success.",
+			req:          func(url string) *Request { return NewRequest().Get(url) },
+			failTimes:    2,
+			wantAttempts: 3,
+		},
+		{
+			name:         "get exceed max attempts.",
+			req:          func(url string) *Request { return NewRequest().Get(url) },
+			failTimes:    5,
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "post without idempotency key not retry.",
+			req:          func(url string) *Request { return NewRequest().Post(url, "body") },
+			failTimes:    1,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name: "post with idempotency key retry and replay body.",
+			req: func(url string) *Request {
+				return NewRequest().Post(url, "body").SetHeader("Idempotency-Key", "abc")
+			},
+			failTimes:    1,
+			wantAttempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if r.Method == http.MethodPost && r.ContentLength != int64(len(`"body"`)) {
+					t.Errorf("attempt %d got content length %d", n, r.ContentLength)
+				}
+				if n <= tt.failTimes {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			sc := NewSimpleClient()
+			sc.SetRetryPolicy(policy)
+			err := sc.Do(tt.req(srv.URL), NewDefaultResponse(nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SimpleClient.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Do_RetryBodyStream(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		RetryStatus: []int{http.StatusServiceUnavailable},
+	}
+
+	tests := []struct {
+		name         string
+		body         func() io.Reader
+		wantAttempts int32
+		wantErr      error
+	}{
+		{
+			name:         "seekable reader replay body.",
+			body:         func() io.Reader { return strings.NewReader("body") },
+			wantAttempts: 2,
+		},
+		{
+			name:         "non rewindable reader not retry.",
+			body:         func() io.Reader { return io.MultiReader(strings.NewReader("body")) },
+			wantAttempts: 1,
+			wantErr:      ErrBodyNotRewindable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if body, _ := ioutil.ReadAll(r.Body); string(body) != "body" {
+					t.Errorf("attempt %d got body %q", n, body)
+				}
+				if n == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			sc := NewSimpleClient()
+			sc.SetRetryPolicy(policy)
+			req := NewRequest().HttpMethod(PUT).Url(srv.URL).SetBodyStream(tt.body(), 0)
+			err := sc.Do(req, NewDefaultResponse(nil))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SimpleClient.Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", got, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package sghtest 提供 sgh.SimpleHttp 的测试替身，用于在没有网络的情况下测试依赖 sgh 的代码。
+package sghtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	sgh "github.com/SmallTianTian/simple-go-http"
+)
+
+// ErrNoStub 在请求未匹配任何 Stub 时返回。
+var ErrNoStub = errors.New("sghtest: no stub matched")
+
+// Client 记录所有请求并返回预设的响应，不发起任何网络请求。
+//
+// Client 内部使用真实的 sgh.SimpleClient，仅替换了 Transport，
+// 因此钩子、中间件、StatusPolicy 与响应解析的行为与线上一致。
+type Client struct {
+	*sgh.SimpleClient
+
+	mu    sync.Mutex
+	stubs []*Stub
+	calls []*sgh.Call
+}
+
+func NewClient() *Client {
+	c := &Client{SimpleClient: sgh.NewSimpleClient()}
+	c.SetTransport(c)
+	return c
+}
+
+// On 注册一个 Stub，url 与请求 URL 完全相同或与去掉 query 后的 URL 相同时匹配。
+// 多个 Stub 均匹配时使用最先注册的一个。
+func (c *Client) On(method sgh.HttpMethod, url string) *Stub {
+	s := &Stub{Method: method, URL: url, StatusCode: http.StatusOK, Header: http.Header{}}
+	c.mu.Lock()
+	c.stubs = append(c.stubs, s)
+	c.mu.Unlock()
+	return s
+}
+
+// Calls 返回按顺序记录的所有请求，Call.Request 为原始的 sgh.Request。
+func (c *Client) Calls() []*sgh.Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*sgh.Call(nil), c.calls...)
+}
+
+// LastCall 返回最后一次请求，没有请求时返回 nil。
+func (c *Client) LastCall() *sgh.Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.calls) == 0 {
+		return nil
+	}
+	return c.calls[len(c.calls)-1]
+}
+
+// Reset 清空所有 Stub 与请求记录。
+func (c *Client) Reset() {
+	c.mu.Lock()
+	c.stubs, c.calls = nil, nil
+	c.mu.Unlock()
+}
+
+// RoundTrip 实现 sgh.Transport。
+func (c *Client) RoundTrip(call *sgh.Call, resp *sgh.Response) error {
+	c.mu.Lock()
+	c.calls = append(c.calls, call)
+	var stub *Stub
+	for _, s := range c.stubs {
+		if s.match(call) {
+			stub = s
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	if stub == nil {
+		return fmt.Errorf("%w: %s %s", ErrNoStub, call.Method, call.URL)
+	}
+	if stub.Err != nil {
+		return stub.Err
+	}
+	resp.StatusCode = stub.StatusCode
+	resp.Status = strconv.Itoa(stub.StatusCode) + " " + http.StatusText(stub.StatusCode)
+	resp.Proto = "HTTP/1.1"
+	resp.Header = stub.Header.Clone()
+	resp.RawBody = append([]byte(nil), stub.Body...)
+	resp.ContentLength = int64(len(stub.Body))
+	return nil
+}
+
+// Stub 是预设的响应。
+type Stub struct {
+	Method     sgh.HttpMethod
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Err 不为 nil 时直接作为请求错误返回
+	Err error
+}
+
+// Reply 设置响应状态码与响应体。
+// body 为 []byte 或 string 时原样返回，其余类型编码为 json 并设置 json 响应头。
+func (s *Stub) Reply(statusCode int, body interface{}) *Stub {
+	s.StatusCode = statusCode
+	switch b := body.(type) {
+	case nil:
+		s.Body = nil
+	case []byte:
+		s.Body = b
+	case string:
+		s.Body = []byte(b)
+	default:
+		s.Body, _ = json.Marshal(b)
+		s.Header.Set("Content-Type", "application/json")
+	}
+	return s
+}
+
+func (s *Stub) SetHeader(key, value string) *Stub {
+	s.Header.Set(key, value)
+	return s
+}
+
+// ReplyError 使匹配的请求返回 err。
+func (s *Stub) ReplyError(err error) *Stub {
+	s.Err = err
+	return s
+}
+
+func (s *Stub) match(call *sgh.Call) bool {
+	if s.Method != call.Method {
+		return false
+	}
+	if s.URL == call.URL {
+		return true
+	}
+	if i := strings.IndexAny(call.URL, "?#"); i >= 0 {
+		return s.URL == call.URL[:i]
+	}
+	return false
+}
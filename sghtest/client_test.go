@@ -0,0 +1,89 @@
+package sghtest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	sgh "github.com/SmallTianTian/simple-go-http"
+)
+
+func TestClient_Do(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	type apiError struct {
+		Message string `json:"message"`
+	}
+	errDial := errors.New("dial failed")
+
+	mock := NewClient()
+	mock.On(sgh.GET, "https://example.com/users").Reply(http.StatusOK, user{Name: "tian"})
+	mock.On(sgh.POST, "https://example.com/users").Reply(http.StatusBadRequest, apiError{Message: "bad"})
+	mock.On(sgh.DELETE, "https://example.com/users").ReplyError(errDial)
+
+	tests := []struct {
+		name     string
+		req      *sgh.Request
+		wantUser user
+		wantErr  func(error) bool
+	}{
+		{
+			name:     "match url without query.",
+			req:      sgh.NewRequest().Get("https://example.com/users").HttpBody(map[string]string{"id": "1"}),
+			wantUser: user{Name: "tian"},
+			wantErr:  func(err error) bool { return err == nil },
+		},
+		{
+			name: "canned error status.",
+			req:  sgh.NewRequest().Post("https://example.com/users", user{Name: "tian"}),
+			wantErr: func(err error) bool {
+				var httpErr *sgh.HTTPError
+				return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusBadRequest
+			},
+		},
+		{
+			name:    "canned transport error.",
+			req:     sgh.NewRequest().HttpMethod(sgh.DELETE).Url("https://example.com/users"),
+			wantErr: func(err error) bool { return errors.Is(err, errDial) },
+		},
+		{
+			name:    "no stub.",
+			req:     sgh.NewRequest().Get("https://example.com/orders"),
+			wantErr: func(err error) bool { return errors.Is(err, ErrNoStub) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got user
+			err := tt.req.Client(mock).Do(sgh.NewDefaultResponse(&got))
+			if !tt.wantErr(err) {
+				t.Errorf("Request.Do() error = %v", err)
+			}
+			if got != tt.wantUser {
+				t.Errorf("Response.Result = %v, want %v", got, tt.wantUser)
+			}
+			if last := mock.LastCall(); last == nil || last.Request != tt.req {
+				t.Errorf("Client.LastCall() = %v, want request recorded", last)
+			}
+		})
+	}
+	if got := len(mock.Calls()); got != len(tests) {
+		t.Errorf("len(Client.Calls()) = %v, want %v", got, len(tests))
+	}
+}
+
+func TestSetDefaultClient(t *testing.T) {
+	mock := NewClient()
+	mock.On(sgh.GET, "https://example.com").Reply(http.StatusOK, `"pong"`)
+	sgh.SetDefaultClient(mock)
+	defer sgh.SetDefaultClient(sgh.NewSimpleClient())
+
+	var got string
+	if err := sgh.NewRequest().Get("https://example.com").Do(sgh.NewJsonResponse(&got)); err != nil {
+		t.Fatalf("Request.Do() error = %v", err)
+	}
+	if got != "pong" {
+		t.Errorf("Response.Result = %v, want %v", got, "pong")
+	}
+}
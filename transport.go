@@ -0,0 +1,139 @@
+package sgh
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Transport 发起一次实际请求，并填充 resp 的状态码、响应头与 RawBody。
+// resp.Stream 为 true 时可改为填充 Body，由调用方读取并关闭。
+type Transport interface {
+	RoundTrip(call *Call, resp *Response) error
+}
+
+// FastTransport 是基于 fasthttp 的 Transport，也是 SimpleClient 的默认实现。
+// 不支持流式响应，resp.Stream 为 true 时返回 ErrStreamNotSupported。
+type FastTransport struct {
+	Client *fasthttp.Client
+}
+
+func (t *FastTransport) RoundTrip(call *Call, resp *Response) error {
+	// fasthttp 总是读取完整响应体，不发起请求以免静默缓冲大文件
+	if resp.Stream {
+		return ErrStreamNotSupported
+	}
+	ctx := call.Ctx
+	// 取 context deadline 与 timeout 中较早的一个
+	deadline := time.Now().Add(call.Timeout)
+	ctxDeadline, hasCtxDeadline := ctx.Deadline()
+	if hasCtxDeadline && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	rq := fasthttp.AcquireRequest()
+	rp := fasthttp.AcquireResponse()
+	if t.Client.DisableHeaderNamesNormalizing {
+		// 需要在添加请求头之前设置，否则添加时已被规范化
+		rq.Header.DisableNormalizing()
+	}
+	request2fastRequest(rq, call)
+
+	if err := t.doDeadline(ctx, rq, rp, deadline); err != nil {
+		if ctxErr := contextError(ctx); ctxErr != nil {
+			return ctxErr
+		}
+		if err == fasthttp.ErrTimeout && hasCtxDeadline && !deadline.Before(ctxDeadline) {
+			return &ContextError{Err: context.DeadlineExceeded}
+		}
+		return err
+	}
+	defer fasthttp.ReleaseRequest(rq)
+	defer fasthttp.ReleaseResponse(rp)
+
+	fastResponse2Response(rp, resp)
+	return nil
+}
+
+// doDeadline 在 ctx 结束时立即返回。
+// fasthttp 无法中断进行中的请求，rq 和 rp 仅在请求成功时交还调用方释放。
+func (t *FastTransport) doDeadline(ctx context.Context, rq *fasthttp.Request, rp *fasthttp.Response, deadline time.Time) error {
+	release := func() {
+		fasthttp.ReleaseRequest(rq)
+		fasthttp.ReleaseResponse(rp)
+	}
+
+	if ctx.Done() == nil {
+		err := t.Client.DoDeadline(rq, rp, deadline)
+		if err != nil {
+			release()
+		}
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- t.Client.DoDeadline(rq, rp, deadline) }()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			release()
+		}
+		return err
+	case <-ctx.Done():
+		go func() {
+			<-errCh
+			release()
+		}()
+		return ctx.Err()
+	}
+}
+
+func request2fastRequest(rq *fasthttp.Request, call *Call) {
+	if call.BodyStream != nil {
+		rq.SetBodyStream(call.BodyStream, int(call.BodySize))
+	} else {
+		rq.AppendBody(call.Body)
+	}
+	rq.SetRequestURI(call.URL)
+	for k, vs := range call.Header {
+		if len(vs) == 0 {
+			rq.Header.Set(k, "")
+		}
+		for _, v := range vs {
+			rq.Header.Add(k, v)
+		}
+	}
+	rq.Header.SetMethod(call.Method.String())
+}
+
+func fastResponse2Response(rs *fasthttp.Response, resp *Response) {
+	head := http.Header{}
+	rs.Header.VisitAll(func(k, v []byte) {
+		head.Add(string(k), string(v))
+	})
+	resp.Header = head
+
+	resp.StatusCode = rs.StatusCode()
+	statusText := string(rs.Header.StatusMessage())
+	if statusText == "" {
+		statusText = fasthttp.StatusMessage(resp.StatusCode)
+	}
+	resp.Status = strconv.Itoa(resp.StatusCode) + " " + statusText
+	resp.Proto = string(rs.Header.Protocol())
+	resp.RawBody = append([]byte(nil), rs.Body()...)
+	resp.ContentLength = int64(rs.Header.ContentLength())
+	if resp.ContentLength < 0 {
+		resp.ContentLength = -1
+	}
+}
+
+// contextError 在 ctx 已结束时返回包装后的 ContextError。
+func contextError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &ContextError{Err: err}
+	}
+	return nil
+}
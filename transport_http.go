@@ -0,0 +1,115 @@
+package sgh
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// HTTPTransport 是基于 net/http 的 Transport，可复用已有的 http.RoundTripper，
+// 支持 HTTP/2 与 net/http 的代理、TLS 配置。
+type HTTPTransport struct {
+	// 为 nil 时使用 http.DefaultTransport
+	RoundTripper http.RoundTripper
+}
+
+func NewHTTPTransport(rt http.RoundTripper) *HTTPTransport {
+	return &HTTPTransport{RoundTripper: rt}
+}
+
+func (t *HTTPTransport) RoundTrip(call *Call, resp *Response) error {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		timer  *time.Timer
+	)
+	if resp.Stream {
+		// 流式响应的超时只作用于获取响应头，响应体由调用方读取并关闭
+		ctx, cancel = context.WithCancel(call.Ctx)
+		timer = time.AfterFunc(call.Timeout, cancel)
+	} else {
+		ctx, cancel = context.WithTimeout(call.Ctx, call.Timeout)
+	}
+	defer func() {
+		if cancel != nil {
+			cancel()
+		}
+	}()
+
+	var body io.Reader
+	if call.BodyStream != nil {
+		body = call.BodyStream
+	} else if len(call.Body) > 0 {
+		body = bytes.NewReader(call.Body)
+	}
+	hr, err := http.NewRequest(call.Method.String(), call.URL, body)
+	if err != nil {
+		return err
+	}
+	if call.BodyStream != nil {
+		hr.ContentLength = call.BodySize
+	}
+	hr = hr.WithContext(ctx)
+	for k, v := range call.Header {
+		hr.Header[k] = append([]string(nil), v...)
+	}
+	if host := hr.Header.Get("Host"); host != "" {
+		hr.Host = host
+	}
+
+	rt := t.RoundTripper
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	hp, err := rt.RoundTrip(hr)
+	if timer != nil && !timer.Stop() {
+		// 与非流式请求一致，超时返回 context.DeadlineExceeded
+		if err == nil {
+			hp.Body.Close()
+		}
+		err = context.DeadlineExceeded
+	}
+	if err != nil {
+		if ctxErr := contextError(call.Ctx); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+
+	resp.Header = hp.Header
+	resp.StatusCode = hp.StatusCode
+	resp.Status = hp.Status
+	resp.Proto = hp.Proto
+	resp.ContentLength = hp.ContentLength
+	if resp.Stream {
+		resp.Body = &cancelBody{ReadCloser: hp.Body, cancel: cancel}
+		cancel = nil
+		return nil
+	}
+	defer hp.Body.Close()
+
+	rawBody, err := ioutil.ReadAll(hp.Body)
+	if err != nil {
+		if ctxErr := contextError(call.Ctx); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	resp.RawBody = rawBody
+	return nil
+}
+
+// cancelBody 在关闭响应体时释放请求的 context。
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
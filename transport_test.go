@@ -0,0 +1,140 @@
+package sgh
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Echo", r.Header.Get("X-Echo"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	transports := map[string]Transport{
+		"fasthttp": NewSimpleClient().transport,
+		"net/http": NewHTTPTransport(nil),
+	}
+	for name, transport := range transports {
+		t.Run(name, func(t *testing.T) {
+			sc := NewSimpleClient()
+			sc.SetTransport(transport)
+
+			var rs map[string]string
+			resp := NewDefaultResponse(&rs)
+			req := NewRequest().
+				Post(srv.URL, map[string]string{"key": "value"}).
+				SetHeader("X-Echo", "echo")
+			if err := sc.Do(req, resp); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if resp.StatusCode != http.StatusCreated || resp.Status != "201 Created" || resp.Proto != "HTTP/1.1" {
+				t.Errorf("Response status = %v %v %v", resp.StatusCode, resp.Status, resp.Proto)
+			}
+			if resp.Header.Get("X-Method") != "POST" || resp.Header.Get("X-Echo") != "echo" {
+				t.Errorf("Response.Header = %v", resp.Header)
+			}
+			if rs["key"] != "value" || resp.ContentLength != int64(len(resp.RawBody)) {
+				t.Errorf("Response.Result = %v, ContentLength = %v", rs, resp.ContentLength)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := sc.Do(NewRequest().Get(srv.URL+"?slow=1").Context(ctx), nil)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("SimpleClient.Do() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestTransport_RoundTrip_Header(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.Header)
+	}))
+	defer srv.Close()
+
+	transports := map[string]Transport{
+		"fasthttp": NewSimpleClient().transport,
+		"net/http": NewHTTPTransport(nil),
+	}
+	for name, transport := range transports {
+		t.Run(name, func(t *testing.T) {
+			req := NewRequest().Get(srv.URL).
+				SetHeader("Accept", "text/html").
+				AddHeader("Accept", "application/json").
+				Headers(map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Remove": "1"}).
+				AddHeader("X-Forwarded-For", "2.2.2.2").
+				DelHeader("X-Remove")
+			var got http.Header
+			if err := NewSimpleClient(WithTransport(transport)).Do(req, NewJsonResponse(&got)); err != nil {
+				t.Fatalf("SimpleClient.Do() error = %v", err)
+			}
+			if v := got["Accept"]; !reflect.DeepEqual(v, []string{"text/html", "application/json"}) {
+				t.Errorf("Accept = %v", v)
+			}
+			if v := got["X-Forwarded-For"]; !reflect.DeepEqual(v, []string{"1.1.1.1", "2.2.2.2"}) {
+				t.Errorf("X-Forwarded-For = %v", v)
+			}
+			if v, ok := got["X-Remove"]; ok {
+				t.Errorf("X-Remove = %v, want deleted", v)
+			}
+		})
+	}
+}
+
+func TestFastTransport_PreserveHeaderCase(t *testing.T) {
+	// net/http 服务端会规范化请求头名称，直接读取原始请求
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	rawCh := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var raw strings.Builder
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			raw.WriteString(line)
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+		rawCh <- raw.String()
+		conn.Write([]byte("HTTP/1.1 204 No Content\r\n\r\n"))
+	}()
+
+	req := NewRequest().Get("http://" + ln.Addr().String())
+	req.Header = http.Header{"x-custom-ID": {"1"}}
+	if err := NewSimpleClient(WithPreserveHeaderCase()).Do(req, nil); err != nil {
+		t.Fatalf("SimpleClient.Do() error = %v", err)
+	}
+	if raw := <-rawCh; !strings.Contains(raw, "\r\nx-custom-ID: 1\r\n") {
+		t.Errorf("raw request = %q, want header name preserved", raw)
+	}
+}
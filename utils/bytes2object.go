@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 )
@@ -9,6 +10,9 @@ func Json2Struct(bs []byte, obj interface{}) error {
 	return json.Unmarshal(bs, obj)
 }
 
+// Xml2Struct 解析 xml 到 obj，按文档声明的 encoding 转换字符集，见 NewCharsetReader。
 func Xml2Struct(bs []byte, obj interface{}) error {
-	return xml.Unmarshal(bs, obj)
+	d := xml.NewDecoder(bytes.NewReader(bs))
+	d.CharsetReader = NewCharsetReader
+	return d.Decode(obj)
 }
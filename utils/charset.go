@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CharsetReader 用于支持内置之外的字符集，签名与 xml.Decoder.CharsetReader 一致，
+// 例如设置为 golang.org/x/net/html/charset.NewReaderLabel。
+var CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+// NewCharsetReader 返回将 charset 编码的 input 转换为 UTF-8 的 reader。
+// 内置支持 utf-8、us-ascii 与 iso-8859-1，其余交给 CharsetReader。
+func NewCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		return latin1Reader{r: input}, nil
+	}
+	if CharsetReader != nil {
+		return CharsetReader(charset, input)
+	}
+	return nil, fmt.Errorf("utils: unsupported charset %q", charset)
+}
+
+// ToUTF8 将 charset 编码的 bs 转换为 UTF-8。
+func ToUTF8(bs []byte, charset string) ([]byte, error) {
+	r, err := NewCharsetReader(charset, bytes.NewReader(bs))
+	if err != nil {
+		return nil, err
+	}
+	if br, ok := r.(*bytes.Reader); ok && br.Size() == int64(len(bs)) {
+		return bs, nil
+	}
+	return ioutil.ReadAll(r)
+}
+
+var xmlEncodingPattern = regexp.MustCompile(`^(\s*<\?xml[^>]*?\bencoding\s*=\s*["'])[^"']*(["'])`)
+
+// XmlDeclareUTF8 将已转换为 UTF-8 的 xml 文档声明的 encoding 改为 UTF-8，避免再次转换。
+func XmlDeclareUTF8(bs []byte) []byte {
+	return xmlEncodingPattern.ReplaceAll(bs, []byte("${1}UTF-8${2}"))
+}
+
+// latin1Reader 将 ISO-8859-1 字节逐个转换为对应的 Unicode 码点。
+type latin1Reader struct {
+	r io.Reader
+}
+
+func (l latin1Reader) Read(p []byte) (int, error) {
+	// 每个字节最多编码为 2 字节，只读取 len(p)/2 保证能放下
+	if len(p) < 2 {
+		return 0, io.ErrShortBuffer
+	}
+	src := make([]byte, len(p)/2)
+	n, err := l.r.Read(src)
+	w := 0
+	for _, b := range src[:n] {
+		w += utf8.EncodeRune(p[w:], rune(b))
+	}
+	return w, err
+}
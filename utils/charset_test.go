@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		bs      string
+		charset string
+		want    string
+		wantErr bool
+	}{
+		{name: "utf8.", bs: "café", charset: "UTF-8", want: "café"},
+		{name: "latin1.", bs: "caf\xe9 \xa3", charset: "ISO-8859-1", want: "café £"},
+		{name: "unsupported.", bs: "a", charset: "gbk", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToUTF8([]byte(tt.bs), tt.charset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToUTF8() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToUTF8() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCharsetReader(t *testing.T) {
+	defer func() { CharsetReader = nil }()
+	CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return strings.NewReader("custom"), nil
+	}
+	if got, err := ToUTF8([]byte("a"), "gbk"); err != nil || string(got) != "custom" {
+		t.Errorf("ToUTF8() = %q, %v, want %q", got, err, "custom")
+	}
+}
+
+func TestXmlDeclareUTF8(t *testing.T) {
+	tests := []struct {
+		bs   string
+		want string
+	}{
+		{bs: `<?xml version="1.0" encoding="ISO-8859-1"?><a/>`, want: `<?xml version="1.0" encoding="UTF-8"?><a/>`},
+		{bs: ` <?xml version='1.0' encoding='gbk'?><a/>`, want: ` <?xml version='1.0' encoding='UTF-8'?><a/>`},
+		{bs: `<?xml version="1.0"?><a encoding="x"/>`, want: `<?xml version="1.0"?><a encoding="x"/>`},
+		{bs: `{"encoding":"x"}`, want: `{"encoding":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bs, func(t *testing.T) {
+			if got := XmlDeclareUTF8([]byte(tt.bs)); string(got) != tt.want {
+				t.Errorf("XmlDeclareUTF8() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
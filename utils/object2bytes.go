@@ -1,58 +1,43 @@
 package utils
 
 import (
-	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"net/url"
 )
 
-func Struct2Json(obj interface{}) []byte {
-	// single
-	switch obj.(type) {
-	case string, int8, int16, int32, int, int64, float32, float64,
-		uint8, uint16, uint32, uint, uint64, bool, uintptr:
-		return []byte(fmt.Sprintf("%v", obj))
-	}
-	bs, _ := json.Marshal(obj)
-	return bs
+// Struct2Json 编码 obj 为 JSON，基础类型同样编码为合法的 JSON，如字符串会加上引号。
+func Struct2Json(obj interface{}) ([]byte, error) {
+	return json.Marshal(obj)
 }
 
-func Struct2Xml(obj interface{}) []byte {
+func Struct2Xml(obj interface{}) ([]byte, error) {
 	// single
 	switch obj.(type) {
 	case string, int8, int16, int32, int, int64, float32, float64,
 		uint8, uint16, uint32, uint, uint64, bool, uintptr:
-		return []byte(fmt.Sprintf("%v", obj))
+		return []byte(fmt.Sprintf("%v", obj)), nil
 	}
-	bs, _ := xml.Marshal(obj)
-	return bs
+	return xml.Marshal(obj)
+}
+
+func Struct2UrlQuery(obj interface{}) ([]byte, error) {
+	return Struct2UrlQueryWithOptions(obj, QueryOptions{})
 }
 
-func Struct2UrlQuery(obj interface{}) []byte {
+// Struct2UrlQueryWithOptions 按 opts 编码 obj 为 url query，基础类型编码为转义后的键名，如 "a b" 编码为 "a+b="。
+func Struct2UrlQueryWithOptions(obj interface{}, opts QueryOptions) ([]byte, error) {
 	// single
 	switch obj.(type) {
 	case string, int8, int16, int32, int, int64, float32, float64,
 		uint8, uint16, uint32, uint, uint64, bool, uintptr:
-		return []byte(fmt.Sprintf("%v=", obj))
+		return []byte(url.QueryEscape(fmt.Sprint(obj)) + "="), nil
 	}
 
-	var m map[string]interface{}
-	json.Unmarshal(Struct2Json(obj), &m)
-	bb := bytes.Buffer{}
-	for k, v := range m {
-		bb.WriteString(k)
-		bb.WriteString("=")
-		var valueStr string
-		switch v.(type) {
-		case string:
-			valueStr = v.(string)
-		default:
-			vbs, _ := json.Marshal(v)
-			valueStr = string(vbs)
-		}
-		bb.WriteString(valueStr)
-		bb.WriteString("&")
+	values, err := Struct2UrlValues(obj, opts)
+	if err != nil {
+		return nil, err
 	}
-	return bb.Bytes()[:bb.Len()-1]
+	return []byte(values.Encode()), nil
 }
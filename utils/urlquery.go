@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArrayStyle 决定切片与数组在 url query 中的编码方式。
+type ArrayStyle uint8
+
+const (
+	ArrayRepeat  ArrayStyle = iota // a=1&a=2
+	ArrayComma                     // a=1,2
+	ArrayBracket                   // a[]=1&a[]=2
+)
+
+// NestStyle 决定嵌套的结构体与 map 在 url query 中的键名。
+type NestStyle uint8
+
+const (
+	NestBracket NestStyle = iota // a[b]=1
+	NestDot                      // a.b=1
+)
+
+// QueryOptions 是 url query 编码的配置，零值即默认配置。
+type QueryOptions struct {
+	ArrayStyle ArrayStyle
+	NestStyle  NestStyle
+	// time.Time 的默认格式，为空时使用 time.RFC3339
+	TimeLayout string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Struct2UrlValues 将结构体、map 或 url.Values 编码为 url.Values。
+//
+// 结构体字段的键名依次取自 `url`、`query`、`json` tag，最后为字段名，
+// tag 为 "-" 时忽略该字段。tag 选项支持：
+//   - omitempty: 零值时忽略
+//   - repeat/comma/brackets: 覆盖 QueryOptions.ArrayStyle
+//
+// time.Time 字段可以通过 `layout` tag 指定格式，实现了 encoding.TextMarshaler 的值使用 MarshalText 编码。
+func Struct2UrlValues(obj interface{}, opts QueryOptions) (url.Values, error) {
+	values := url.Values{}
+	if obj == nil {
+		return values, nil
+	}
+	if vs, ok := obj.(url.Values); ok {
+		for k, v := range vs {
+			values[k] = append(values[k], v...)
+		}
+		return values, nil
+	}
+
+	e := &queryEncoder{opts: opts, values: values}
+	v := indirect(reflect.ValueOf(obj))
+	switch v.Kind() {
+	case reflect.Invalid:
+		return values, nil
+	case reflect.Struct, reflect.Map:
+		if err := e.encode("", v, fieldOptions{}); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("utils: url query expects struct or map, got %s", v.Type())
+}
+
+type fieldOptions struct {
+	omitEmpty  bool
+	arrayStyle *ArrayStyle
+	layout     string
+}
+
+type queryEncoder struct {
+	opts   QueryOptions
+	values url.Values
+}
+
+func (e *queryEncoder) encode(key string, v reflect.Value, fo fieldOptions) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if key != "" {
+				e.values.Add(key, "")
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if s, ok, err := e.scalar(v, fo); ok || err != nil {
+		if err != nil {
+			return fmt.Errorf("utils: encode url query %q: %w", key, err)
+		}
+		e.values.Add(key, s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(key, v)
+	case reflect.Map:
+		return e.encodeMap(key, v)
+	case reflect.Slice, reflect.Array:
+		return e.encodeSlice(key, v, fo)
+	}
+	return fmt.Errorf("utils: encode url query %q: unsupported type %s", key, v.Type())
+}
+
+// scalar 将 v 编码为单个字符串，v 不是标量时 ok 为 false。
+func (e *queryEncoder) scalar(v reflect.Value, fo fieldOptions) (s string, ok bool, err error) {
+	if v.Type() == timeType {
+		layout := fo.layout
+		if layout == "" {
+			layout = e.opts.TimeLayout
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(layout), true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		bs, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(bs), true, err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		bs, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(bs), true, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	case reflect.Slice:
+		// []byte 视为字符串
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (e *queryEncoder) encodeStruct(key string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		name, fo := parseQueryTag(sf)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fo.omitEmpty && fv.IsZero() {
+			continue
+		}
+		// 未指定键名的匿名结构体字段展开到当前层级
+		if sf.Anonymous && name == sf.Name {
+			st := sf.Type
+			if st.Kind() == reflect.Ptr {
+				st = st.Elem()
+			}
+			if st.Kind() == reflect.Struct && st != timeType {
+				// 为 nil 的匿名结构体指针没有字段可展开
+				if ev := indirect(fv); ev.IsValid() {
+					if err := e.encodeStruct(key, ev); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+		}
+		if err := e.encode(e.nestKey(key, name), fv, fo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *queryEncoder) encodeMap(key string, v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	byName := make(map[string]reflect.Value, v.Len())
+	for _, mk := range v.MapKeys() {
+		name := fmt.Sprint(mk.Interface())
+		keys = append(keys, name)
+		byName[name] = v.MapIndex(mk)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		if err := e.encode(e.nestKey(key, name), byName[name], fieldOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *queryEncoder) encodeSlice(key string, v reflect.Value, fo fieldOptions) error {
+	style := e.opts.ArrayStyle
+	if fo.arrayStyle != nil {
+		style = *fo.arrayStyle
+	}
+
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := indirect(v.Index(i))
+		if !elem.IsValid() {
+			items = append(items, "")
+			continue
+		}
+		s, ok, err := e.scalar(elem, fo)
+		if err != nil {
+			return fmt.Errorf("utils: encode url query %q: %w", key, err)
+		}
+		if !ok {
+			// 非标量元素使用下标作为嵌套键
+			if err := e.encode(e.nestKey(key, strconv.Itoa(i)), elem, fieldOptions{}); err != nil {
+				return err
+			}
+			continue
+		}
+		items = append(items, s)
+	}
+
+	switch style {
+	case ArrayComma:
+		if len(items) > 0 {
+			e.values.Add(key, strings.Join(items, ","))
+		}
+	case ArrayBracket:
+		for _, s := range items {
+			e.values.Add(key+"[]", s)
+		}
+	default:
+		for _, s := range items {
+			e.values.Add(key, s)
+		}
+	}
+	return nil
+}
+
+func (e *queryEncoder) nestKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if e.opts.NestStyle == NestDot {
+		return parent + "." + name
+	}
+	return parent + "[" + name + "]"
+}
+
+func parseQueryTag(sf reflect.StructField) (string, fieldOptions) {
+	tag, ok := sf.Tag.Lookup("url")
+	if !ok {
+		tag, ok = sf.Tag.Lookup("query")
+	}
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = sf.Name
+	}
+	fo := fieldOptions{layout: sf.Tag.Get("layout")}
+	for _, opt := range parts[1:] {
+		var style ArrayStyle
+		switch opt {
+		case "omitempty":
+			fo.omitEmpty = true
+			continue
+		case "repeat":
+			style = ArrayRepeat
+		case "comma":
+			style = ArrayComma
+		case "brackets":
+			style = ArrayBracket
+		default:
+			continue
+		}
+		fo.arrayStyle = &style
+	}
+	return name, fo
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// UrlQuery2Struct 解析 url query 或 application/x-www-form-urlencoded 数据到 obj。
+func UrlQuery2Struct(bs []byte, obj interface{}) error {
+	values, err := url.ParseQuery(string(bs))
+	if err != nil {
+		return err
+	}
+	return UrlValues2Struct(values, obj, QueryOptions{})
+}
+
+// UrlValues2Struct 是 Struct2UrlValues 的逆过程，obj 必须是指针。
+// 支持 *url.Values、*map[string]string、*map[string][]string、*map[string]interface{} 与结构体指针。
+func UrlValues2Struct(values url.Values, obj interface{}, opts QueryOptions) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("utils: decode url query expects non-nil pointer, got %T", obj)
+	}
+	d := &queryDecoder{opts: opts, values: values}
+	v := rv.Elem()
+	switch v.Kind() {
+	case reflect.Map:
+		return d.decodeMap(v)
+	case reflect.Struct:
+		return d.decodeStruct("", v)
+	}
+	return fmt.Errorf("utils: decode url query into unsupported type %T", obj)
+}
+
+type queryDecoder struct {
+	opts   QueryOptions
+	values url.Values
+}
+
+func (d *queryDecoder) decodeMap(v reflect.Value) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("utils: decode url query into unsupported type %s", t)
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(d.values)))
+	}
+	for k, vs := range d.values {
+		var ev reflect.Value
+		switch {
+		case t.Elem().Kind() == reflect.String && len(vs) == 0:
+			// 没有值的键无法填入单个字符串
+			continue
+		case t.Elem().Kind() == reflect.String:
+			ev = reflect.ValueOf(vs[0])
+		case t.Elem() == reflect.TypeOf([]string(nil)):
+			ev = reflect.ValueOf(append([]string(nil), vs...))
+		case t.Elem().Kind() == reflect.Interface && len(vs) == 1:
+			ev = reflect.ValueOf(vs[0])
+		case t.Elem().Kind() == reflect.Interface:
+			ev = reflect.ValueOf(append([]string(nil), vs...))
+		default:
+			return fmt.Errorf("utils: decode url query into unsupported type %s", t)
+		}
+		v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev.Convert(t.Elem()))
+	}
+	return nil
+}
+
+func (d *queryDecoder) decodeStruct(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		name, fo := parseQueryTag(sf)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous && name == sf.Name {
+			if sf.Type.Kind() == reflect.Struct {
+				if err := d.decodeStruct(prefix, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := d.decodeField(d.nestKey(prefix, name), fv, fo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *queryDecoder) decodeField(key string, v reflect.Value, fo fieldOptions) error {
+	if v.Kind() == reflect.Ptr {
+		if !d.has(key) {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeField(key, v.Elem(), fo)
+	}
+
+	if d.isScalar(v) {
+		vs := d.values[key]
+		if len(vs) == 0 {
+			return nil
+		}
+		return d.setScalar(key, v, vs[0], fo)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(key, v)
+	case reflect.Slice:
+		style := d.opts.ArrayStyle
+		if fo.arrayStyle != nil {
+			style = *fo.arrayStyle
+		}
+		var items []string
+		switch style {
+		case ArrayComma:
+			if vs := d.values[key]; len(vs) > 0 && vs[0] != "" {
+				items = strings.Split(vs[0], ",")
+			}
+		case ArrayBracket:
+			items = d.values[key+"[]"]
+		default:
+			items = d.values[key]
+		}
+		if items == nil {
+			return nil
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, s := range items {
+			ev := slice.Index(i)
+			if ev.Kind() == reflect.Ptr {
+				ev.Set(reflect.New(ev.Type().Elem()))
+				ev = ev.Elem()
+			}
+			// 元素只能是标量，与编码时一致
+			if !d.isScalar(ev) {
+				return fmt.Errorf("utils: decode url query %q: unsupported type %s", key, v.Type())
+			}
+			if err := d.setScalar(key, ev, s, fo); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return fmt.Errorf("utils: decode url query %q: unsupported type %s", key, v.Type())
+}
+
+func (d *queryDecoder) isScalar(v reflect.Value) bool {
+	if v.Type() == timeType || reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+func (d *queryDecoder) setScalar(key string, v reflect.Value, s string, fo fieldOptions) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("utils: decode url query %q: %w", key, err)
+		}
+	}()
+
+	if v.Type() == timeType {
+		layout := fo.layout
+		if layout == "" {
+			layout = d.opts.TimeLayout
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	}
+	return nil
+}
+
+// has 判断是否存在 key 或以 key 为前缀的嵌套参数。
+func (d *queryDecoder) has(key string) bool {
+	for k := range d.values {
+		if k == key || strings.HasPrefix(k, key+"[") || strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *queryDecoder) nestKey(parent, name string) string {
+	return (&queryEncoder{opts: d.opts}).nestKey(parent, name)
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStruct2UrlValues(t *testing.T) {
+	type Inner struct {
+		City string `url:"city"`
+		Zip  string `url:"zip,omitempty"`
+	}
+	type Embedded struct {
+		Page int `url:"page"`
+	}
+	type Query struct {
+		Embedded
+		Name     string    `url:"name"`
+		Empty    string    `url:"empty,omitempty"`
+		Skip     string    `url:"-"`
+		Tags     []string  `url:"tags"`
+		Ids      []int     `url:"ids,comma"`
+		Flags    []bool    `url:"flags,brackets"`
+		Address  Inner     `url:"address"`
+		Created  time.Time `url:"created"`
+		Birthday time.Time `url:"birthday" layout:"2006-01-02"`
+		IP       net.IP    `url:"ip"`
+		Ptr      *int      `url:"ptr"`
+		Score    float64   `query:"score"`
+		JsonName string    `json:"json_name"`
+		NoTag    string
+		private  string
+	}
+	type EmbeddedPtr struct {
+		*Embedded
+		B int `url:"b"`
+	}
+	created := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		obj     interface{}
+		opts    QueryOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "struct with tags.",
+			obj: Query{
+				Embedded: Embedded{Page: 2},
+				Name:     "a&b c",
+				Skip:     "skip",
+				Tags:     []string{"x", "y"},
+				Ids:      []int{1, 2},
+				Flags:    []bool{true, false},
+				Address:  Inner{City: "sh"},
+				Created:  created,
+				Birthday: created,
+				IP:       net.ParseIP("127.0.0.1"),
+				Score:    1.5,
+				JsonName: "j",
+				NoTag:    "n",
+				private:  "p",
+			},
+			want: "NoTag=n&address%5Bcity%5D=sh&birthday=2022-03-04&created=2022-03-04T05%3A06%3A07Z" +
+				"&flags%5B%5D=true&flags%5B%5D=false&ids=1%2C2&ip=127.0.0.1&json_name=j&name=a%26b+c&page=2&ptr=&score=1.5&tags=x&tags=y",
+		},
+		{
+			name: "dot nest style and bracket array style.",
+			obj: map[string]interface{}{
+				"address": map[string]string{"city": "sh"},
+				"tags":    []string{"x", "y"},
+			},
+			opts: QueryOptions{NestStyle: NestDot, ArrayStyle: ArrayBracket},
+			want: "address.city=sh&tags%5B%5D=x&tags%5B%5D=y",
+		},
+		{
+			name: "map sorted.",
+			obj:  map[string]interface{}{"b": 2, "a": "1", "c": 1.234},
+			want: "a=1&b=2&c=1.234",
+		},
+		{
+			name: "url values.",
+			obj:  url.Values{"k": []string{"v1", "v2"}},
+			want: "k=v1&k=v2",
+		},
+		{
+			name: "time layout option.",
+			obj:  map[string]time.Time{"t": created},
+			opts: QueryOptions{TimeLayout: "2006"},
+			want: "t=2022",
+		},
+		{
+			name: "slice of struct use index.",
+			obj:  map[string][]Inner{"items": {{City: "a"}, {City: "b"}}},
+			want: "items%5B0%5D%5Bcity%5D=a&items%5B1%5D%5Bcity%5D=b",
+		},
+		{
+			name: "nil embedded struct pointer.",
+			obj:  EmbeddedPtr{B: 1},
+			want: "b=1",
+		},
+		{
+			name: "embedded struct pointer.",
+			obj:  EmbeddedPtr{Embedded: &Embedded{Page: 2}, B: 1},
+			want: "b=1&page=2",
+		},
+		{
+			name: "empty struct.",
+			obj:  struct{}{},
+			want: "",
+		},
+		{
+			name: "nil.",
+			obj:  nil,
+			want: "",
+		},
+		{
+			name:    "unsupported type.",
+			obj:     map[string]interface{}{"ch": make(chan int)},
+			wantErr: true,
+		},
+		{
+			name:    "not struct or map.",
+			obj:     []int{1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Struct2UrlValues(tt.obj, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Struct2UrlValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Encode() != tt.want {
+				t.Errorf("Struct2UrlValues() = %v, want %v", got.Encode(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStruct2UrlQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface{}
+		want string
+	}{
+		{name: "single.", obj: "single", want: "single="},
+		{name: "escaped single.", obj: "a b&c", want: "a+b%26c="},
+		{name: "map.", obj: map[string]string{"k": "a b"}, want: "k=a+b"},
+		{name: "empty struct not panic.", obj: struct{}{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := Struct2UrlQuery(tt.obj); string(got) != tt.want {
+				t.Errorf("Struct2UrlQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUrlValues2Struct(t *testing.T) {
+	type Inner struct {
+		City string `url:"city"`
+	}
+	type Form struct {
+		Name     string    `url:"name"`
+		Age      int       `url:"age"`
+		Score    float64   `url:"score"`
+		Ok       bool      `url:"ok"`
+		Tags     []string  `url:"tags"`
+		Ids      []int     `url:"ids,comma"`
+		Flags    []bool    `url:"flags,brackets"`
+		Address  Inner     `url:"address"`
+		Ptr      *Inner    `url:"ptr"`
+		Missing  *Inner    `url:"missing"`
+		Birthday time.Time `url:"birthday" layout:"2006-01-02"`
+		IP       net.IP    `url:"ip"`
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		values  url.Values
+		obj     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "struct.",
+			query: "name=a%26b+c&age=18&score=1.5&ok=true&tags=x&tags=y&ids=1%2C2&flags%5B%5D=true" +
+				"&address%5Bcity%5D=sh&ptr%5Bcity%5D=bj&birthday=2022-03-04&ip=127.0.0.1",
+			obj: &Form{},
+			want: &Form{
+				Name:     "a&b c",
+				Age:      18,
+				Score:    1.5,
+				Ok:       true,
+				Tags:     []string{"x", "y"},
+				Ids:      []int{1, 2},
+				Flags:    []bool{true},
+				Address:  Inner{City: "sh"},
+				Ptr:      &Inner{City: "bj"},
+				Birthday: time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+				IP:       net.ParseIP("127.0.0.1"),
+			},
+		},
+		{
+			name:  "map string.",
+			query: "a=1&a=2&b=3",
+			obj:   &map[string]string{},
+			want:  &map[string]string{"a": "1", "b": "3"},
+		},
+		{
+			name:  "map interface.",
+			query: "a=1&a=2&b=3",
+			obj:   &map[string]interface{}{},
+			want:  &map[string]interface{}{"a": []string{"1", "2"}, "b": "3"},
+		},
+		{
+			name:  "url values.",
+			query: "a=1&a=2",
+			obj:   &url.Values{},
+			want:  &url.Values{"a": []string{"1", "2"}},
+		},
+		{
+			name:    "invalid number.",
+			query:   "age=old",
+			obj:     &Form{},
+			wantErr: true,
+		},
+		{
+			name:   "empty values map.",
+			values: url.Values{"k": {}, "b": {"3"}},
+			obj:    &map[string]string{},
+			want:   &map[string]string{"b": "3"},
+		},
+		{
+			name:   "empty values struct.",
+			values: url.Values{"name": {}, "ids": {}, "age": {"18"}},
+			obj:    &Form{},
+			want:   &Form{Age: 18},
+		},
+		{
+			name:  "slice of struct.",
+			query: "items=a",
+			obj: &struct {
+				Items []Inner `url:"items"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:    "not pointer.",
+			query:   "a=1",
+			obj:     Form{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.values != nil {
+				err = UrlValues2Struct(tt.values, tt.obj, QueryOptions{})
+			} else {
+				err = UrlQuery2Struct([]byte(tt.query), tt.obj)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UrlQuery2Struct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.obj, tt.want) {
+				t.Errorf("UrlQuery2Struct() = %+v, want %+v", tt.obj, tt.want)
+			}
+		})
+	}
+}